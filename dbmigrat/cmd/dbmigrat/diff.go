@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"path/filepath"
+
+	"github.com/graaphscom/monogo/dbmigrat"
+	"github.com/graaphscom/monogo/dbmigrat/gitsource"
+)
+
+func diff(args []string) error {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	manifest := flags.String("manifest", "dbmigrat.yaml", "path to manifest file")
+	base := flags.String("base", "", "path to base manifest file (default: -manifest read from -rev)")
+	rev := flags.String("rev", "", "git revision of repository containing current directory to read base manifest from (e.g. main)")
+	format := flags.String("format", "table", "output format: table or json")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	headMigrations, _, err := readManifest(*manifest)
+	if err != nil {
+		return err
+	}
+	baseMigrations, err := readBaseManifest(*manifest, *base, *rev)
+	if err != nil {
+		return err
+	}
+
+	result := dbmigrat.DiffMigrations(baseMigrations, headMigrations)
+	err = printResult(*format, result, result.Table())
+	if err != nil {
+		return err
+	}
+	if *format == "table" {
+		for _, repo := range result.Repos {
+			for _, change := range repo.Changes {
+				printSQLDiff(repo.Repo, change, "up", change.UpDiff)
+				printSQLDiff(repo.Repo, change, "down", change.DownDiff)
+			}
+		}
+	}
+	if result.ChangesExisting() {
+		return errChangedMigrations
+	}
+
+	return nil
+}
+
+func readBaseManifest(manifest string, base string, rev string) (dbmigrat.Migrations, error) {
+	if rev == "" {
+		if base == "" {
+			return nil, errNoBase
+		}
+		migrations, _, err := readManifest(base)
+		return migrations, err
+	}
+	if base == "" {
+		base = manifest
+	}
+	fileSys, err := gitsource.TreeFS(".", rev)
+	if err != nil {
+		return nil, err
+	}
+	treePath, err := worktreePath(base)
+	if err != nil {
+		return nil, err
+	}
+	migrations, _, err := dbmigrat.ReadManifest(fileSys, treePath)
+	return migrations, err
+}
+
+// worktreePath returns path (relative to current directory) relative to the root of git repository,
+// as used by gitsource.TreeFS.
+func worktreePath(path string) (string, error) {
+	root, err := gitsource.WorktreeRoot(".")
+	if err != nil {
+		return "", err
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	relPath, err := filepath.Rel(root, absPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(relPath), nil
+}
+
+func printSQLDiff(repo dbmigrat.Repo, change dbmigrat.MigrationChange, direction string, sqlDiff string) {
+	if sqlDiff == "" {
+		return
+	}
+	fmt.Printf("\n%s %d %s (%s):\n%s", repo, change.HeadIdx, change.Description, direction, sqlDiff)
+}
+
+var (
+	errNoBase            = errors.New("either -base or -rev is required")
+	errChangedMigrations = errors.New("existing migrations were changed")
+)
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/graaphscom/monogo/dbmigrat"
+)
+
+func lint(args []string) error {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	manifest := flags.String("manifest", "dbmigrat.yaml", "path to manifest file")
+	bigTables := flags.String("big-tables", "", "comma separated tables on which index must be created concurrently (by default all tables not created in the same migration)")
+	disabledRules := flags.String("disable", "", "comma separated rules which are not checked")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	migrations, _, err := readManifest(*manifest)
+	if err != nil {
+		return err
+	}
+
+	opts := dbmigrat.LintOptions{BigTables: splitList(*bigTables)}
+	for _, rule := range splitList(*disabledRules) {
+		opts.DisabledRules = append(opts.DisabledRules, dbmigrat.LintRule(rule))
+	}
+	issues := dbmigrat.Lint(migrations, opts)
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("found %d issues", len(issues))
+	}
+
+	return nil
+}
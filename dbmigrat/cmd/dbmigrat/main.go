@@ -0,0 +1,72 @@
+// Command dbmigrat allows for working with migrations described by dbmigrat manifest (see dbmigrat.ReadManifest).
+//
+// Usage:
+//
+//	dbmigrat <command> [flags]
+//
+// Run "dbmigrat <command> -h" for command's flags.
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/graaphscom/monogo/dbmigrat"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+	err := cmd.run(os.Args[2:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: dbmigrat <command> [flags]\n\nCommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].description)
+	}
+}
+
+var commands = map[string]command{
+	"check":    {description: "check whether migrations log matches migrations from manifest", run: check},
+	"diff":     {description: "compare migrations from manifest with base manifest (e.g. from main branch)", run: diff},
+	"lint":     {description: "check migrations' SQL for risky patterns", run: lint},
+	"new":      {description: "create files for the next migration in repo's directory", run: newMigration},
+	"renumber": {description: "move migrations (e.g. from unmerged branch) after remaining ones in repo's directory", run: renumber},
+	"status":   {description: "show applied and pending migrations", run: status},
+}
+
+type command struct {
+	description string
+	run         func(args []string) error
+}
+
+func readManifest(path string) (dbmigrat.Migrations, dbmigrat.RepoOrder, error) {
+	return dbmigrat.ReadManifest(os.DirFS(filepath.Dir(path)), filepath.Base(path))
+}
+
+func splitList(list string) []string {
+	if list == "" {
+		return nil
+	}
+	return strings.Split(list, ",")
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/graaphscom/monogo/dbmigrat"
+)
+
+func newMigration(args []string) error {
+	flags := flag.NewFlagSet("new", flag.ExitOnError)
+	dir := flags.String("dir", ".", "path to repo's migrations directory")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: dbmigrat new [flags] <description>")
+		flags.PrintDefaults()
+	}
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("missing migration's description")
+	}
+
+	upPath, downPath, err := dbmigrat.CreateMigrationFiles(*dir, strings.Join(flags.Args(), " "))
+	if err != nil {
+		return err
+	}
+	fmt.Println(upPath)
+	fmt.Println(downPath)
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+
+	"github.com/graaphscom/monogo/dbmigrat"
+)
+
+func renumber(args []string) error {
+	flags := flag.NewFlagSet("renumber", flag.ExitOnError)
+	dir := flags.String("dir", ".", "path to repo's migrations directory")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: dbmigrat renumber [flags] <description>...")
+		flags.PrintDefaults()
+	}
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("missing descriptions of migrations to renumber")
+	}
+
+	renamed, err := dbmigrat.RenumberMigrationFiles(*dir, flags.Args()...)
+	oldPaths := make([]string, 0, len(renamed))
+	for oldPath := range renamed {
+		oldPaths = append(oldPaths, oldPath)
+	}
+	sort.Strings(oldPaths)
+	for _, oldPath := range oldPaths {
+		fmt.Printf("%s -> %s\n", oldPath, renamed[oldPath])
+	}
+
+	return err
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/graaphscom/monogo/asciiui"
+	"github.com/graaphscom/monogo/dbmigrat"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+func status(args []string) error {
+	flags := flag.NewFlagSet("status", flag.ExitOnError)
+	dbURL, manifest, format := dbFlags(flags)
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	migrations, repoOrder, err := readManifest(*manifest)
+	if err != nil {
+		return err
+	}
+	store, err := openStore(*dbURL)
+	if err != nil {
+		return err
+	}
+	defer store.DB.Close()
+
+	result, err := dbmigrat.FetchStatus(store, migrations, repoOrder)
+	if err != nil {
+		return err
+	}
+
+	return printResult(*format, result, result.Table())
+}
+
+func check(args []string) error {
+	flags := flag.NewFlagSet("check", flag.ExitOnError)
+	dbURL, manifest, format := dbFlags(flags)
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	migrations, _, err := readManifest(*manifest)
+	if err != nil {
+		return err
+	}
+	store, err := openStore(*dbURL)
+	if err != nil {
+		return err
+	}
+	defer store.DB.Close()
+
+	result, err := dbmigrat.CheckLogTableIntegrity(store, migrations)
+	if err != nil {
+		return err
+	}
+
+	err = printResult(*format, result, result.Table())
+	if err != nil {
+		return err
+	}
+	if result.IsCorrupted {
+		return errCorruptedLog
+	}
+
+	return nil
+}
+
+func dbFlags(flags *flag.FlagSet) (dbURL *string, manifest *string, format *string) {
+	dbURL = flags.String("db", os.Getenv("DBMIGRAT_DB_URL"), "database connection string (default $DBMIGRAT_DB_URL)")
+	manifest = flags.String("manifest", "dbmigrat.yaml", "path to manifest file")
+	format = flags.String("format", "table", "output format: table or json")
+	return dbURL, manifest, format
+}
+
+func openStore(dbURL string) (*dbmigrat.PostgresStore, error) {
+	db, err := sqlx.Connect("postgres", dbURL)
+	if err != nil {
+		return nil, err
+	}
+	return &dbmigrat.PostgresStore{DB: db}, nil
+}
+
+func printResult(format string, result interface{}, table asciiui.Table) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	case "table":
+		rendered, err := table.Render()
+		if err != nil {
+			return err
+		}
+		fmt.Print(rendered)
+		return nil
+	default:
+		return fmt.Errorf("%w (%s)", errUnknownFormat, format)
+	}
+}
+
+var (
+	errCorruptedLog  = errors.New("migrations log is corrupted")
+	errUnknownFormat = errors.New("unknown output format")
+)
@@ -0,0 +1,48 @@
+package dbmigrat
+
+// conditionsEvaluator checks whether migration's conditions (environments, flags and extensions) are met.
+// Migrations which conditions are not met are skipped by Migrate and not saved in migrations log.
+// Skipped migration stays pending (see FetchStatus) and is applied by the first Migrate meeting its conditions
+// (e.g. after installing extension), even when migrations following it were applied already.
+type conditionsEvaluator struct {
+	s          store
+	opts       MigrateOptions
+	extensions map[string]bool
+}
+
+func (c *conditionsEvaluator) met(m Migration) (bool, error) {
+	if len(m.Environments) > 0 && !contains(m.Environments, c.opts.Environment) {
+		return false, nil
+	}
+	for _, flag := range m.Flags {
+		if !contains(c.opts.Flags, flag) {
+			return false, nil
+		}
+	}
+	if len(m.Extensions) > 0 && c.extensions == nil {
+		extensions, err := c.s.fetchAvailableExtensions()
+		if err != nil {
+			return false, err
+		}
+		c.extensions = extensions
+	}
+	for _, extension := range m.Extensions {
+		if !c.extensions[extension] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (m Migration) conditional() bool {
+	return len(m.Environments) > 0 || len(m.Flags) > 0 || len(m.Extensions) > 0
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
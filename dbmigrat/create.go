@@ -0,0 +1,112 @@
+package dbmigrat
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// CreateMigrationFiles creates empty up and down files for the next migration in directory under dirPath.
+// Files follow ReadDir convention: N.description.up.sql and N.description.down.sql,
+// where N is the next index after indexes of migrations already present in the directory.
+// Spaces in description are replaced with underscores.
+//
+// It returns error when directory contains migrations with the same index but different descriptions
+// (e.g. added in concurrent branches), gaps in indexes or migrations without up or down file.
+// Such errors list conflicting files, so the migration can be renumbered before running ReadDir.
+func CreateMigrationFiles(dirPath string, description string) (upPath string, downPath string, err error) {
+	description = strings.ReplaceAll(strings.TrimSpace(description), " ", "_")
+	if description == "" || strings.ContainsAny(description, `./\`) {
+		return "", "", errInvalidDescription
+	}
+
+	dirEntries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return "", "", err
+	}
+	fileNames := make([]string, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() {
+			return "", "", errContainsDirectory
+		}
+		fileNames = append(fileNames, dirEntry.Name())
+	}
+
+	nextIdx, err := nextMigrationIdx(fileNames)
+	if err != nil {
+		return "", "", err
+	}
+
+	upPath = filepath.Join(dirPath, fmt.Sprintf("%d.%s.%s.sql", nextIdx, description, up))
+	downPath = filepath.Join(dirPath, fmt.Sprintf("%d.%s.%s.sql", nextIdx, description, down))
+	err = createEmptyFile(upPath)
+	if err != nil {
+		return "", "", err
+	}
+	err = createEmptyFile(downPath)
+	if err != nil {
+		// Up file left alone would conflict with the next migration created in directory.
+		removeErr := os.Remove(upPath)
+		if removeErr != nil {
+			return "", "", multierror.Append(err, removeErr)
+		}
+		return "", "", err
+	}
+
+	return upPath, downPath, nil
+}
+
+func createEmptyFile(path string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// nextMigrationIdx validates files names against ReadDir convention and returns index for the next migration.
+func nextMigrationIdx(fileNames []string) (int, error) {
+	parsedFN, err := parseFileNames(fileNames)
+	if err != nil {
+		return 0, err
+	}
+
+	filesByIdx := map[int]parsedFileNames{}
+	for _, parsed := range parsedFN {
+		filesByIdx[parsed.idx] = append(filesByIdx[parsed.idx], parsed)
+	}
+	for idx := 0; idx < len(filesByIdx); idx++ {
+		files, ok := filesByIdx[idx]
+		if !ok {
+			return 0, fmt.Errorf("%w (missing index %d)", errIndexGap, idx)
+		}
+		names := make([]string, 0, len(files))
+		descriptions := map[string]bool{}
+		directions := map[direction]int{}
+		for _, file := range files {
+			names = append(names, file.fileName)
+			descriptions[file.description] = true
+			directions[file.direction]++
+		}
+		sort.Strings(names)
+		if len(descriptions) > 1 {
+			return 0, fmt.Errorf("%w (%s)", errIndexConflict, strings.Join(names, ", "))
+		}
+		if directions[up] != 1 || directions[down] != 1 {
+			return 0, fmt.Errorf("%w (%s)", errSameDirections, strings.Join(names, ", "))
+		}
+	}
+
+	return len(filesByIdx), nil
+}
+
+var (
+	errInvalidDescription = errors.New("migration's description must not be empty and must not contain dots or slashes")
+	errIndexGap           = errors.New("indexes of migrations files must be incrementing by one")
+	errIndexConflict      = errors.New("migrations files with the same index have different descriptions (migrations added in concurrent branches?)")
+)
@@ -0,0 +1,71 @@
+package dbmigrat
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateMigrationFiles(t *testing.T) {
+	t.Run("creates first migration", func(t *testing.T) {
+		dir := createFiles(t)
+		upPath, downPath, err := CreateMigrationFiles(dir, "create users table")
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "0.create_users_table.up.sql"), upPath)
+		assert.Equal(t, filepath.Join(dir, "0.create_users_table.down.sql"), downPath)
+		assert.FileExists(t, upPath)
+		assert.FileExists(t, downPath)
+	})
+
+	t.Run("creates next migration readable by ReadDir", func(t *testing.T) {
+		dir := createFiles(t, "0.a.up.sql", "0.a.down.sql", "1.b.up", "1.b.down")
+		upPath, _, err := CreateMigrationFiles(dir, "c")
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "2.c.up.sql"), upPath)
+		migrations, err := ReadDir(os.DirFS(dir), ".")
+		assert.NoError(t, err)
+		assert.Len(t, migrations, 3)
+	})
+
+	t.Run("removes up file when down file can't be created", func(t *testing.T) {
+		dir := createFiles(t)
+		// # Name of up file fits in 255 bytes, name of down file doesn't
+		_, _, err := CreateMigrationFiles(dir, strings.Repeat("a", 245))
+		assert.Error(t, err)
+		dirEntries, err := os.ReadDir(dir)
+		assert.NoError(t, err)
+		assert.Empty(t, dirEntries)
+	})
+
+	caseTable := []struct {
+		name        string
+		fileNames   []string
+		description string
+		errExpected string
+	}{
+		{name: "invalid description", description: "v1.2", errExpected: errInvalidDescription.Error()},
+		{name: "conflicting indexes", fileNames: []string{"0.a.up", "0.a.down", "1.b.up", "1.b.down", "1.c.up", "1.c.down"}, description: "d", errExpected: errIndexConflict.Error() + " (1.b.down, 1.b.up, 1.c.down, 1.c.up)"},
+		{name: "gap", fileNames: []string{"0.a.up", "0.a.down", "2.b.up", "2.b.down"}, description: "d", errExpected: errIndexGap.Error() + " (missing index 1)"},
+		{name: "missing down", fileNames: []string{"0.a.up", "0.a.down", "1.b.up"}, description: "d", errExpected: errSameDirections.Error() + " (1.b.up)"},
+		{name: "invalid file name", fileNames: []string{"a.b.up"}, description: "d", errExpected: errFileNameIdx.Error()},
+	}
+	for _, testCase := range caseTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			dir := createFiles(t, testCase.fileNames...)
+			_, _, err := CreateMigrationFiles(dir, testCase.description)
+			assert.EqualError(t, err, testCase.errExpected)
+		})
+	}
+}
+
+// createFiles creates files in temporary directory. Content of every file is its name.
+func createFiles(t *testing.T, fileNames ...string) string {
+	dir := t.TempDir()
+	for _, fileName := range fileNames {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, fileName), []byte(fileName), 0600))
+	}
+	return dir
+}
@@ -6,6 +6,7 @@ import (
 	"crypto/sha1"
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/hashicorp/go-multierror"
 )
@@ -21,20 +22,37 @@ import (
 // determines order in which values from migrations map will be applied.
 // e.g. if migrations in repo "A" have foreign keys to repo "B" - then repoOrder should be {"B", "A"}
 func Migrate(s store, migrations Migrations, repoOrder RepoOrder) (int, error) {
-	err := s.begin()
+	return MigrateWithOptions(s, migrations, repoOrder, MigrateOptions{})
+}
+
+// MigrateWithOptions works like Migrate, but allows for limiting applied migrations with MigrateOptions.
+func MigrateWithOptions(s store, migrations Migrations, repoOrder RepoOrder, opts MigrateOptions) (int, error) {
+	if !opts.Phase.valid() {
+		return 0, errUnknownPhase
+	}
+	err := opts.Target.validate(migrations)
+	if err != nil {
+		return 0, err
+	}
+	err = opts.Repeatable.validate()
 	if err != nil {
 		return 0, err
 	}
 
-	logCount, err := migrate(s, migrations, repoOrder)
+	err = s.begin()
 	if err != nil {
-		return 0, multierror.Append(err, s.rollback())
+		return 0, err
+	}
+
+	logCount, err := migrate(s, migrations, repoOrder, opts)
+	if err != nil {
+		return 0, insertFailureHistory(s, multierror.Append(err, s.rollback()), opts.Actor)
 	}
 
 	return logCount, s.commit()
 }
 
-func migrate(s store, migrations Migrations, repoOrder RepoOrder) (int, error) {
+func migrate(s store, migrations Migrations, repoOrder RepoOrder, opts MigrateOptions) (int, error) {
 	lastMigrationSerial, err := s.fetchLastMigrationSerial()
 	if err != nil {
 		return 0, err
@@ -46,42 +64,169 @@ func migrate(s store, migrations Migrations, repoOrder RepoOrder) (int, error) {
 		return 0, err
 	}
 
+	var appliedIndexes map[Repo]map[int]bool
+	if opts.OutOfOrder || migrations.versioned() || migrations.conditional() {
+		appliedLogs, err := s.fetchAllMigrationLogs()
+		if err != nil {
+			return 0, err
+		}
+		err = checkVersionsApplied(migrations, appliedLogs)
+		if err != nil {
+			return 0, err
+		}
+		appliedIndexes = appliedIndexesByRepo(appliedLogs)
+	}
+
+	conditions := conditionsEvaluator{s: s, opts: opts}
 	var insertedLogsCount int
 	for _, orderedRepo := range repoOrder {
 		repoMigrations, ok := migrations[orderedRepo]
 		if !ok {
 			continue
 		}
-		lastMigrationIdx, ok := lastMigrationIndexes[orderedRepo]
-		if !ok {
-			lastMigrationIdx = -1
+		if opts.Target != nil {
+			targetIdx, ok := opts.Target[orderedRepo]
+			if !ok {
+				continue
+			}
+			repoMigrations = repoMigrations[:targetIdx+1]
 		}
-		if len(repoMigrations) <= lastMigrationIdx+1 {
-			continue
+		firstIdx := 0
+		if !opts.OutOfOrder {
+			lastMigrationIdx, ok := lastMigrationIndexes[orderedRepo]
+			if !ok {
+				lastMigrationIdx = -1
+			}
+			firstIdx = lastMigrationIdx + 1
+		}
+
+		startIdx := firstIdx
+		if appliedIndexes != nil {
+			startIdx = 0
 		}
 
-		var logs []migrationLog
-		for i, migrationToRun := range repoMigrations[lastMigrationIdx+1:] {
-			err = s.exec(migrationToRun.Up)
+		var logs []MigrationLog
+		for idx := startIdx; idx < len(repoMigrations); idx++ {
+			if appliedIndexes[orderedRepo][idx] {
+				continue
+			}
+			migrationToRun := repoMigrations[idx]
+			// conditional migration skipped by previous run is applied once its conditions are met
+			skippedBefore := idx < firstIdx
+			if skippedBefore && !migrationToRun.conditional() {
+				continue
+			}
+			if opts.Phase != AnyPhase && migrationToRun.phase() != opts.Phase {
+				if skippedBefore {
+					continue
+				}
+				break
+			}
+			met, err := conditions.met(migrationToRun)
 			if err != nil {
 				return 0, err
 			}
-			logs = append(logs, migrationLog{
-				Idx:             lastMigrationIdx + 1 + i,
+			if !met {
+				continue
+			}
+			log := MigrationLog{
+				Idx:             idx,
 				Repo:            orderedRepo,
 				MigrationSerial: migrationSerial,
 				Checksum:        sha1Checksum(migrationToRun.Up),
 				Description:     migrationToRun.Description,
-			})
+			}
+			if migrationToRun.NoTransaction {
+				err = insertLogs(s, logs, opts.Actor)
+				if err != nil {
+					return 0, err
+				}
+				insertedLogsCount += len(logs)
+				logs = nil
+				err = outsideTransaction(s, func() error {
+					err := execMigration(s, migrationToRun.Up, &log, HistoryApply, opts.Actor)
+					if err != nil {
+						return err
+					}
+					return insertLogs(s, []MigrationLog{log}, opts.Actor)
+				})
+				if err != nil {
+					return 0, err
+				}
+				insertedLogsCount++
+				continue
+			}
+			err = execMigration(s, migrationToRun.Up, &log, HistoryApply, opts.Actor)
+			if err != nil {
+				return 0, err
+			}
+			logs = append(logs, log)
 		}
-		err = s.insertLogs(logs)
+		err = insertLogs(s, logs, opts.Actor)
 		if err != nil {
 			return 0, err
 		}
 		insertedLogsCount += len(logs)
 	}
 
-	return insertedLogsCount, nil
+	repeatableCount, err := migrateRepeatable(s, migrations, repoOrder, migrationSerial, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	return insertedLogsCount + repeatableCount, nil
+}
+
+// conditional reports whether any migration is conditional (see Migration.Environments).
+func (m Migrations) conditional() bool {
+	for _, repoMigrations := range m {
+		for _, migration := range repoMigrations {
+			if migration.conditional() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// versioned reports whether any migration has Version (see TimestampVersions).
+func (m Migrations) versioned() bool {
+	for _, repoMigrations := range m {
+		for _, migration := range repoMigrations {
+			if migration.Version != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkVersionsApplied returns error when applied migration of repo with versions
+// is now at other position (e.g. migration with lower version was inserted before it).
+// Changed checksum of migration which stayed at its position is reported by CheckLogTableIntegrity.
+func checkVersionsApplied(migrations Migrations, logs []MigrationLog) error {
+	for _, log := range logs {
+		repoMigrations := migrations[log.Repo]
+		if log.Idx >= len(repoMigrations) || repoMigrations[log.Idx].Version == 0 {
+			continue
+		}
+		if log.Checksum == sha1Checksum(repoMigrations[log.Idx].Up) {
+			continue
+		}
+		if log.Description != repoMigrations[log.Idx].Description || containsChecksum(repoMigrations, log.Checksum) {
+			return fmt.Errorf("%w (repo: %s, idx: %d, version: %d)", errVersionInsertedBeforeApplied, log.Repo, log.Idx, repoMigrations[log.Idx].Version)
+		}
+	}
+	return nil
+}
+
+func containsChecksum(migrations []Migration, checksum string) bool {
+	for _, migration := range migrations {
+		if sha1Checksum(migration.Up) == checksum {
+			return true
+		}
+	}
+	return false
 }
 
 // Rollback rolls back migrations applied by Migrate func
@@ -90,24 +235,47 @@ func migrate(s store, migrations Migrations, repoOrder RepoOrder) (int, error) {
 //
 // migration serial represents applied migrations (from different repos) in single run of Migrate func.
 // When toMigrationSerial == -1, then all applied migrations will be rolled back.
+//
+// When any of migrations to roll back is irreversible, then no migration is rolled back
+// and returned error contains *IrreversibleMigrationError.
 func Rollback(s store, migrations Migrations, repoOrder RepoOrder, toMigrationSerial int) (int, error) {
+	return RollbackWithOptions(s, migrations, repoOrder, toMigrationSerial, RollbackOptions{})
+}
+
+// RollbackWithOptions works like Rollback, but accepts RollbackOptions.
+func RollbackWithOptions(s store, migrations Migrations, repoOrder RepoOrder, toMigrationSerial int, opts RollbackOptions) (int, error) {
 	err := s.begin()
 	if err != nil {
 		return 0, err
 	}
-	deletedLogs, err := rollback(s, migrations, repoOrder, toMigrationSerial)
+	deletedLogs, err := rollback(s, migrations, repoOrder, toMigrationSerial, opts)
 	if err != nil {
-		return 0, multierror.Append(err, s.rollback())
+		return 0, insertFailureHistory(s, multierror.Append(err, s.rollback()), opts.Actor)
 	}
 	return deletedLogs, s.commit()
 }
 
-func rollback(s store, migrations Migrations, repoOrder RepoOrder, toMigrationSerial int) (int, error) {
+func rollback(s store, migrations Migrations, repoOrder RepoOrder, toMigrationSerial int, opts RollbackOptions) (int, error) {
 	repoToReverseIndexes, err := s.fetchReverseMigrationIndexesAfterSerial(toMigrationSerial)
 	if err != nil {
 		return 0, err
 	}
-	var logsToDelete []migrationLog
+	if len(repoToReverseIndexes) == 0 {
+		return 0, nil
+	}
+	appliedLogs, err := s.fetchAllMigrationLogs()
+	if err != nil {
+		return 0, err
+	}
+	appliedLogsByIdx := map[Repo]map[int]MigrationLog{}
+	for _, log := range appliedLogs {
+		if appliedLogsByIdx[log.Repo] == nil {
+			appliedLogsByIdx[log.Repo] = map[int]MigrationLog{}
+		}
+		appliedLogsByIdx[log.Repo][log.Idx] = log
+	}
+
+	var logsToDelete []MigrationLog
 	for _, orderedRepo := range repoOrder {
 		reverseIndexes, ok := repoToReverseIndexes[orderedRepo]
 		if !ok {
@@ -117,14 +285,44 @@ func rollback(s store, migrations Migrations, repoOrder RepoOrder, toMigrationSe
 			if len(migrations[orderedRepo]) <= migrationIdx {
 				return 0, errMigrationsOutSync
 			}
-			err := s.exec(migrations[orderedRepo][migrationIdx].Down)
+			if migrations[orderedRepo][migrationIdx].Irreversible {
+				return 0, &IrreversibleMigrationError{
+					Repo:        orderedRepo,
+					Idx:         migrationIdx,
+					Description: migrations[orderedRepo][migrationIdx].Description,
+				}
+			}
+			logsToDelete = append(logsToDelete, appliedLogsByIdx[orderedRepo][migrationIdx])
+		}
+	}
+	var pendingLogs []MigrationLog
+	for _, log := range logsToDelete {
+		migrationToRollback := migrations[log.Repo][log.Idx]
+		if migrationToRollback.NoTransaction {
+			err = deleteLogs(s, pendingLogs, opts.Actor)
 			if err != nil {
 				return 0, err
 			}
-			logsToDelete = append(logsToDelete, migrationLog{Idx: migrationIdx, Repo: orderedRepo})
+			pendingLogs = nil
+			err = outsideTransaction(s, func() error {
+				err := execMigration(s, migrationToRollback.Down, &log, HistoryRollback, opts.Actor)
+				if err != nil {
+					return err
+				}
+				return deleteLogs(s, []MigrationLog{log}, opts.Actor)
+			})
+			if err != nil {
+				return 0, err
+			}
+			continue
 		}
+		err := execMigration(s, migrationToRollback.Down, &log, HistoryRollback, opts.Actor)
+		if err != nil {
+			return 0, err
+		}
+		pendingLogs = append(pendingLogs, log)
 	}
-	err = s.deleteLogs(logsToDelete)
+	err = deleteLogs(s, pendingLogs, opts.Actor)
 	if err != nil {
 		return 0, err
 	}
@@ -132,12 +330,123 @@ func rollback(s store, migrations Migrations, repoOrder RepoOrder, toMigrationSe
 	return len(logsToDelete), nil
 }
 
+func appliedIndexesByRepo(logs []MigrationLog) map[Repo]map[int]bool {
+	result := map[Repo]map[int]bool{}
+	for _, log := range logs {
+		if result[log.Repo] == nil {
+			result[log.Repo] = map[int]bool{}
+		}
+		result[log.Repo][log.Idx] = true
+	}
+	return result
+}
+
+// outsideTransaction commits current transaction, calls fn and begins new transaction.
+// It allows for running statements which can not be executed inside a transaction block
+// (e.g. create index concurrently).
+func outsideTransaction(s store, fn func() error) error {
+	if _, ok := s.(externalTransactionStore); ok {
+		return errNoTransactionInExternalTx
+	}
+	err := s.commit()
+	if err != nil {
+		return err
+	}
+	err = fn()
+	if err != nil {
+		return err
+	}
+	return s.begin()
+}
+
 type Migrations map[Repo][]Migration
 
 type Migration struct {
 	Description string
 	Up          string
 	Down        string
+	// Irreversible marks migration which can not be rolled back (Down is not executed).
+	Irreversible bool
+	// NoTransaction makes Up and Down run outside of transaction.
+	// Migrations applied (or rolled back) before such migration are committed
+	// and stay applied even when later migration fails.
+	NoTransaction bool
+	// Phase allows for applying migration before (PhaseExpand) or after (PhaseContract) deploying new code.
+	// Migration without phase belongs to PhaseExpand.
+	Phase Phase
+	// Environments restrict migration to given environments (see MigrateOptions.Environment).
+	// Migration without environments is applied in every environment.
+	Environments []string
+	// Flags restrict migration to runs with all given flags set (see MigrateOptions.Flags), e.g. seed data.
+	Flags []string
+	// Extensions restrict migration to databases where all given Postgres extensions are available.
+	Extensions []string
+	// Version is the version from file name of migration read with TimestampVersions, zero otherwise.
+	Version int64
+	// Tags mark migration as the last migration of its repo in tagged releases (see ReleaseTarget).
+	// Migration can have several tags when repo did not change between releases.
+	Tags []string
+}
+
+func (m Migration) phase() Phase {
+	if m.Phase == AnyPhase {
+		return PhaseExpand
+	}
+	return m.Phase
+}
+
+// MigrateOptions allows for limiting migrations applied by MigrateWithOptions.
+// Zero value applies all pending migrations.
+type MigrateOptions struct {
+	// Phase limits applied migrations to ones from given phase.
+	// Pending migrations of every repo are applied until the first migration from other phase,
+	// so migrations are never applied out of order.
+	Phase Phase
+	// Actor is saved in migrations history. Defaults to the current OS user name.
+	Actor string
+	// Target limits applied migrations to ones up to given index in every repo.
+	// Nil Target applies all pending migrations.
+	Target Target
+	// OutOfOrder applies migrations missing from migrations log even when their index is lower
+	// than index of the last applied migration (e.g. hotfix migration merged late).
+	// By default, only migrations following the last applied migration are applied.
+	OutOfOrder bool
+	// Environment is the environment (e.g. "dev") in which migrations are applied.
+	// Migrations restricted to other environments are skipped (see Migration.Environments).
+	Environment string
+	// Flags enable migrations requiring them (see Migration.Flags).
+	Flags []string
+	// Repeatable migrations are applied in repoOrder after versioned migrations of all repos,
+	// when they were not applied yet or their Up has changed since the last apply.
+	// Applied repeatable migrations are included in count returned by MigrateWithOptions
+	// and saved in history with Idx -1 and migration name as Description.
+	// Repeatable migrations of repo are applied only when its schema is complete: they are skipped
+	// for PhaseExpand and for repos which Target excludes or doesn't reach the last migration of.
+	Repeatable RepeatableMigrations
+}
+
+// RollbackOptions allows for configuring RollbackWithOptions.
+type RollbackOptions struct {
+	// Actor is saved in migrations history. Defaults to the current OS user name.
+	Actor string
+}
+
+// Phase is used for zero-downtime deployments (expand/contract pattern).
+// Additive changes (e.g. new tables, nullable columns) belong to PhaseExpand
+// and are applied before deploying new code.
+// Destructive changes (e.g. dropping columns used by old code) belong to PhaseContract
+// and are applied after old code is gone.
+type Phase string
+
+const (
+	// AnyPhase passed to MigrateWithOptions applies migrations from all phases.
+	AnyPhase      Phase = ""
+	PhaseExpand   Phase = "expand"
+	PhaseContract Phase = "contract"
+)
+
+func (p Phase) valid() bool {
+	return p == AnyPhase || p == PhaseExpand || p == PhaseContract
 }
 
 type RepoOrder []Repo
@@ -148,8 +457,38 @@ type RepoOrder []Repo
 // while billing migrations in repo "billing".
 type Repo string
 
+func sortedRepos[V any](m map[Repo]V) []Repo {
+	repos := make([]Repo, 0, len(m))
+	for repo := range m {
+		repos = append(repos, repo)
+	}
+	sort.Slice(repos, func(i, j int) bool { return repos[i] < repos[j] })
+	return repos
+}
+
 func sha1Checksum(data string) string {
 	return fmt.Sprintf("%x", sha1.Sum([]byte(data)))
 }
 
-var errMigrationsOutSync = errors.New("migrations passed to Rollback func are not in sync with migrations log. You might want to run CheckLogTableIntegrity func")
+// IrreversibleMigrationError is returned when Rollback would need to roll back irreversible migration.
+type IrreversibleMigrationError struct {
+	Repo        Repo
+	Idx         int
+	Description string
+}
+
+func (e *IrreversibleMigrationError) Error() string {
+	return fmt.Sprintf("migration %d (%s) in repo %s is irreversible, refusing to roll back", e.Idx, e.Description, e.Repo)
+}
+
+var (
+	errMigrationsOutSync            = errors.New("migrations passed to Rollback func are not in sync with migrations log. You might want to run CheckLogTableIntegrity func")
+	errVersionInsertedBeforeApplied = errors.New("migration with given version is at position of other applied migration, it was probably inserted before applied migrations and needs higher version")
+	errTargetOutOfRange             = errors.New("target index is out of range of repo migrations")
+	errDuplicatedRepeatable         = errors.New("repo contains more than one repeatable migration with given name")
+	errNoTransactionInExternalTx    = errors.New("migration with NoTransaction can not run in transaction managed by caller")
+	errMetaVersionTooNew            = errors.New("dbmigrat tables were upgraded by newer version of dbmigrat")
+	errUnknownTag                   = errors.New("no migration is tagged with given tag")
+	errDuplicatedTag                = errors.New("repo contains more than one migration with given tag")
+	errUnknownPhase                 = fmt.Errorf("phase must be one of: %q, %q, %q", AnyPhase, PhaseExpand, PhaseContract)
+)
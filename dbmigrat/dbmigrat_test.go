@@ -50,6 +50,7 @@ func TestMigrateError(t *testing.T) {
 		{name: "fetchLastMigrationIndexes fail", storeMock: errorStoreMock{wrapped: th.pgStore, errFetchLastMigrationIndexes: true}, errExpected: exampleMultiErr},
 		{name: "exec fail", storeMock: errorStoreMock{wrapped: th.pgStore, errExec: true}, errExpected: exampleMultiErr},
 		{name: "insertLogs fail", storeMock: errorStoreMock{wrapped: th.pgStore, errInsertLogs: true}, errExpected: exampleMultiErr},
+		{name: "insertHistory fail", storeMock: errorStoreMock{wrapped: th.pgStore, errInsertHistory: true}, errExpected: exampleMultiErr},
 	}
 
 	for _, testCase := range caseTable {
@@ -61,6 +62,179 @@ func TestMigrateError(t *testing.T) {
 	}
 }
 
+func TestMigratePhases(t *testing.T) {
+	assert.NoError(t, th.resetDB())
+	assert.NoError(t, th.pgStore.CreateLogTable())
+	migrations := Migrations{
+		"auth": {
+			th.migrations1["auth"][0],
+			{Up: `alter table users add column username varchar(32)`, Down: `alter table users drop column username`, Phase: PhaseExpand},
+			{Up: `alter table users add column login varchar(32)`, Down: `alter table users drop column login`},
+			{Up: `alter table users drop column login`, Down: `alter table users add column login varchar(32)`, Phase: PhaseContract},
+			{Up: `alter table users add column email varchar(255)`, Down: `alter table users drop column email`},
+		},
+		"billing": th.migrations1["billing"],
+	}
+
+	logCount, err := MigrateWithOptions(th.pgStore, migrations, RepoOrder{"auth", "billing"}, MigrateOptions{Phase: PhaseExpand})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, logCount)
+
+	// # Expand phase stops at contract migration
+	logCount, err = MigrateWithOptions(th.pgStore, migrations, RepoOrder{"auth", "billing"}, MigrateOptions{Phase: PhaseExpand})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, logCount)
+
+	logCount, err = MigrateWithOptions(th.pgStore, migrations, RepoOrder{"auth", "billing"}, MigrateOptions{Phase: PhaseContract})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, logCount)
+
+	logCount, err = Migrate(th.pgStore, migrations, RepoOrder{"auth", "billing"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, logCount)
+
+	logCount, err = MigrateWithOptions(th.pgStore, migrations, RepoOrder{"auth", "billing"}, MigrateOptions{Phase: "unknown"})
+	assert.EqualError(t, err, errUnknownPhase.Error())
+	assert.Equal(t, 0, logCount)
+}
+
+func TestMigrateTarget(t *testing.T) {
+	assert.NoError(t, th.resetDB())
+	assert.NoError(t, th.pgStore.CreateLogTable())
+
+	logCount, err := MigrateWithOptions(th.pgStore, th.migrations2, RepoOrder{"auth", "billing", "delivery"}, MigrateOptions{Target: Target{"auth": 0}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, logCount)
+
+	logCount, err = MigrateWithOptions(th.pgStore, th.migrations2, RepoOrder{"auth", "billing", "delivery"}, MigrateOptions{Target: Target{"auth": 1, "billing": 0}})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, logCount)
+
+	// # Target lower than applied migrations does not roll back anything
+	logCount, err = MigrateWithOptions(th.pgStore, th.migrations2, RepoOrder{"auth", "billing", "delivery"}, MigrateOptions{Target: Target{"auth": 0, "billing": -1}})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, logCount)
+
+	logCount, err = MigrateWithOptions(th.pgStore, th.migrations2, RepoOrder{"auth", "billing", "delivery"}, MigrateOptions{Target: Target{"billing": 2}})
+	assert.ErrorIs(t, err, errTargetOutOfRange)
+	assert.Equal(t, 0, logCount)
+
+	logCount, err = Migrate(th.pgStore, th.migrations2, RepoOrder{"auth", "billing", "delivery"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, logCount)
+}
+
+func TestMigrateVersions(t *testing.T) {
+	s := &MemoryStore{}
+	branchA := Migration{Up: `create table a ()`, Down: `drop table a`, Description: "a", Version: 20230301}
+	branchB := Migration{Up: `create table b ()`, Down: `drop table b`, Description: "b", Version: 20230215}
+	_, err := Migrate(s, Migrations{"auth": {branchA}}, RepoOrder{"auth"})
+	assert.NoError(t, err)
+
+	// # Branch with older timestamp merged after deploying newer one
+	_, err = Migrate(s, Migrations{"auth": {branchB, branchA}}, RepoOrder{"auth"})
+	assert.ErrorIs(t, err, errVersionInsertedBeforeApplied)
+	assert.Equal(t, []string{branchA.Up}, s.Executed)
+
+	// # Inserted migration is detected by description even when applied one was edited too
+	editedA := branchA
+	editedA.Up = `create table a (id integer)`
+	_, err = Migrate(s, Migrations{"auth": {branchB, editedA}}, RepoOrder{"auth"})
+	assert.ErrorIs(t, err, errVersionInsertedBeforeApplied)
+
+	// # Edited applied migration is left for CheckLogTableIntegrity
+	branchB.Version = 20230302
+	logCount, err := Migrate(s, Migrations{"auth": {editedA, branchB}}, RepoOrder{"auth"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, logCount)
+}
+
+func TestMigrateOutOfOrder(t *testing.T) {
+	assert.NoError(t, th.resetDB())
+	assert.NoError(t, th.pgStore.CreateLogTable())
+	hotfix := Migration{Up: `create table hotfix (id integer)`, Down: `drop table hotfix`, Description: "hotfix"}
+	migrationsWithoutHotfix := Migrations{"auth": {th.migrations1["auth"][0], {Up: `select 1`, Description: "placeholder"}, th.migrations1["auth"][1]}}
+	migrationsWithHotfix := Migrations{"auth": {th.migrations1["auth"][0], hotfix, th.migrations1["auth"][1]}}
+
+	// # Apply migrations around the hotfix and remove it from log as it was merged late
+	_, err := Migrate(th.pgStore, migrationsWithoutHotfix, RepoOrder{"auth"})
+	assert.NoError(t, err)
+	assert.NoError(t, th.pgStore.deleteLogs([]MigrationLog{{Idx: 1, Repo: "auth"}}))
+
+	logCount, err := Migrate(th.pgStore, migrationsWithHotfix, RepoOrder{"auth"})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, logCount)
+
+	logCount, err = MigrateWithOptions(th.pgStore, migrationsWithHotfix, RepoOrder{"auth"}, MigrateOptions{OutOfOrder: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, logCount)
+	_, err = th.db.Exec(`select * from hotfix`)
+	assert.NoError(t, err)
+
+	// # Late hotfix is rolled back first as it was applied last
+	logCount, err = Rollback(th.pgStore, migrationsWithHotfix, RepoOrder{"auth"}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, logCount)
+	_, err = th.db.Exec(`select * from hotfix`)
+	assert.Error(t, err)
+}
+
+func TestMigrateConditions(t *testing.T) {
+	assert.NoError(t, th.resetDB())
+	assert.NoError(t, th.pgStore.CreateLogTable())
+	migrations := Migrations{
+		"auth": {
+			th.migrations1["auth"][0],
+			{Up: `insert into users default values`, Down: `delete from users`, Description: "seed users", Environments: []string{"dev"}, Flags: []string{"seed"}},
+			{Up: `create extension if not exists plpgsql`, Down: `select 1`, Description: "plpgsql", Extensions: []string{"plpgsql"}},
+			{Up: `create extension not_existing`, Down: `select 1`, Description: "not existing extension", Extensions: []string{"not_existing"}},
+		},
+	}
+
+	logCount, err := MigrateWithOptions(th.pgStore, migrations, RepoOrder{"auth"}, MigrateOptions{Environment: "dev"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, logCount)
+
+	// # Skipped migrations are not reported as gaps and are applied once their conditions are met
+	result, err := CheckLogTableIntegrity(th.pgStore, migrations)
+	assert.NoError(t, err)
+	assert.Equal(t, newIntegrityCheckResult(), result)
+
+	logCount, err = MigrateWithOptions(th.pgStore, migrations, RepoOrder{"auth"}, MigrateOptions{Environment: "dev", Flags: []string{"seed"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, logCount)
+	var usersCount int
+	assert.NoError(t, th.db.Get(&usersCount, `select count(*) from users`))
+	assert.Equal(t, 1, usersCount)
+
+	t.Run("fetchAvailableExtensions fail", func(t *testing.T) {
+		assert.NoError(t, th.resetDB())
+		assert.NoError(t, th.pgStore.CreateLogTable())
+		logCount, err := Migrate(errorStoreMock{wrapped: th.pgStore, errFetchAvailableExtensions: true}, migrations, RepoOrder{"auth"})
+		assert.EqualError(t, err, exampleMultiErr.Error())
+		assert.Equal(t, 0, logCount)
+	})
+}
+
+func TestMigrateConditionsMetLater(t *testing.T) {
+	s := &MemoryStore{}
+	migrations := Migrations{"geo": {
+		{Up: `create table places ()`},
+		{Up: `create index places_geo_idx on places using gist (geo)`, Extensions: []string{"postgis"}},
+		{Up: `create table routes ()`},
+	}}
+	logCount, err := Migrate(s, migrations, RepoOrder{"geo"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, logCount)
+
+	// # Extension installed after applying following migrations
+	s.Extensions = map[string]bool{"postgis": true}
+	logCount, err = Migrate(s, migrations, RepoOrder{"geo"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, logCount)
+	assert.Equal(t, []string{migrations["geo"][0].Up, migrations["geo"][2].Up, migrations["geo"][1].Up}, s.Executed)
+}
+
 func TestRollback(t *testing.T) {
 	before := func(t *testing.T) {
 		assert.NoError(t, th.resetDB())
@@ -86,6 +260,7 @@ func TestRollback(t *testing.T) {
 			{name: "tx begin fail", storeMock: errorStoreMock{wrapped: th.pgStore, errBegin: true}, errExpected: exampleErr},
 			{name: "fetchReverseMigrationIndexesAfterSerial fail", storeMock: errorStoreMock{wrapped: th.pgStore, errFetchReverseMigrationIndexesAfterSerial: true}, errExpected: exampleMultiErr},
 			{name: "exec fail", storeMock: errorStoreMock{wrapped: th.pgStore, errExec: true}, errExpected: exampleMultiErr},
+			{name: "fetchAllMigrationLogs fail", storeMock: errorStoreMock{wrapped: th.pgStore, errFetchAllMigrationLogs: true}, errExpected: exampleMultiErr},
 			{name: "deleteLogs fail", storeMock: errorStoreMock{wrapped: th.pgStore, errDeleteLogs: true}, errExpected: exampleMultiErr},
 		}
 
@@ -97,6 +272,18 @@ func TestRollback(t *testing.T) {
 			})
 		}
 
+		t.Run("irreversible migration", func(t *testing.T) {
+			billing := append([]Migration{}, th.migrations2["billing"]...)
+			billing[1].Irreversible = true
+			migrations := Migrations{"auth": th.migrations2["auth"], "billing": billing, "delivery": th.migrations2["delivery"]}
+
+			logCount, err := Rollback(th.pgStore, migrations, RepoOrder{"delivery", "billing", "auth"}, 0)
+			var irreversibleErr *IrreversibleMigrationError
+			assert.ErrorAs(t, err, &irreversibleErr)
+			assert.Equal(t, &IrreversibleMigrationError{Repo: "billing", Idx: 1, Description: "add value gross column"}, irreversibleErr)
+			assert.Equal(t, 0, logCount)
+		})
+
 		t.Run("too less migrations provided", func(t *testing.T) {
 			logCount, err := Rollback(th.pgStore, th.migrations1, RepoOrder{"delivery", "billing", "auth"}, 0)
 			assert.EqualError(t, err, multierror.Append(errMigrationsOutSync).Error())
@@ -105,6 +292,32 @@ func TestRollback(t *testing.T) {
 	})
 }
 
+func TestNoTransaction(t *testing.T) {
+	assert.NoError(t, th.resetDB())
+	assert.NoError(t, th.pgStore.CreateLogTable())
+	migrations := Migrations{
+		"auth": append(th.migrations1["auth"], Migration{
+			Up:            `create index concurrently users_username_idx on users (username)`,
+			Down:          `drop index concurrently users_username_idx`,
+			Description:   "index username",
+			NoTransaction: true,
+		}),
+		"billing": th.migrations1["billing"],
+	}
+
+	logCount, err := Migrate(th.pgStore, migrations, RepoOrder{"auth", "billing"})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, logCount)
+
+	logCount, err = Rollback(th.pgStore, migrations, RepoOrder{"billing", "auth"}, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, logCount)
+
+	var migrationLogs []MigrationLog
+	assert.NoError(t, th.db.Select(&migrationLogs, `select * from dbmigrat_log`))
+	assert.Empty(t, migrationLogs)
+}
+
 type caseTable []struct {
 	name        string
 	storeMock   store
@@ -117,7 +330,7 @@ func (s errorStoreMock) CreateLogTable() error {
 	}
 	return s.wrapped.CreateLogTable()
 }
-func (s errorStoreMock) fetchAllMigrationLogs() ([]migrationLog, error) {
+func (s errorStoreMock) fetchAllMigrationLogs() ([]MigrationLog, error) {
 	if s.errFetchAllMigrationLogs {
 		return nil, exampleErr
 	}
@@ -129,7 +342,7 @@ func (s errorStoreMock) fetchLastMigrationSerial() (int, error) {
 	}
 	return s.wrapped.fetchLastMigrationSerial()
 }
-func (s errorStoreMock) insertLogs(logs []migrationLog) error {
+func (s errorStoreMock) insertLogs(logs []MigrationLog) error {
 	if s.errInsertLogs {
 		return exampleErr
 	}
@@ -147,12 +360,48 @@ func (s errorStoreMock) fetchReverseMigrationIndexesAfterSerial(serial int) (map
 	}
 	return s.wrapped.fetchReverseMigrationIndexesAfterSerial(serial)
 }
-func (s errorStoreMock) deleteLogs(logs []migrationLog) error {
+func (s errorStoreMock) deleteLogs(logs []MigrationLog) error {
 	if s.errDeleteLogs {
 		return exampleErr
 	}
 	return s.wrapped.deleteLogs(logs)
 }
+func (s errorStoreMock) insertHistory(entries []HistoryEntry) error {
+	if s.errInsertHistory {
+		return exampleErr
+	}
+	return s.wrapped.insertHistory(entries)
+}
+func (s errorStoreMock) fetchHistory() ([]HistoryEntry, error) {
+	if s.errFetchHistory {
+		return nil, exampleErr
+	}
+	return s.wrapped.fetchHistory()
+}
+func (s errorStoreMock) fetchAvailableExtensions() (map[string]bool, error) {
+	if s.errFetchAvailableExtensions {
+		return nil, exampleErr
+	}
+	return s.wrapped.fetchAvailableExtensions()
+}
+func (s errorStoreMock) fetchRepeatableLogs() ([]RepeatableLog, error) {
+	if s.errFetchRepeatableLogs {
+		return nil, exampleErr
+	}
+	return s.wrapped.fetchRepeatableLogs()
+}
+func (s errorStoreMock) upsertRepeatableLog(log RepeatableLog) error {
+	if s.errUpsertRepeatableLog {
+		return exampleErr
+	}
+	return s.wrapped.upsertRepeatableLog(log)
+}
+func (s errorStoreMock) fetchImportedVersions(tool importedTool, table quotedIdentifier) ([]importedVersion, error) {
+	if s.errFetchImportedVersions {
+		return nil, exampleErr
+	}
+	return s.wrapped.fetchImportedVersions(tool, table)
+}
 func (s errorStoreMock) begin() error {
 	if s.errBegin {
 		return exampleErr
@@ -171,9 +420,9 @@ func (s errorStoreMock) commit() error {
 	}
 	return s.wrapped.commit()
 }
-func (s errorStoreMock) exec(query string) error {
+func (s errorStoreMock) exec(query string) (int64, error) {
 	if s.errExec {
-		return exampleErr
+		return 0, exampleErr
 	}
 	return s.wrapped.exec(query)
 }
@@ -192,6 +441,12 @@ type errorStoreMock struct {
 	errFetchLastMigrationIndexes               bool
 	errFetchReverseMigrationIndexesAfterSerial bool
 	errDeleteLogs                              bool
+	errInsertHistory                           bool
+	errFetchHistory                            bool
+	errFetchAvailableExtensions                bool
+	errFetchRepeatableLogs                     bool
+	errUpsertRepeatableLog                     bool
+	errFetchImportedVersions                   bool
 	errBegin                                   bool
 	errRollback                                bool
 	errCommit                                  bool
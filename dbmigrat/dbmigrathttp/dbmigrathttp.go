@@ -0,0 +1,204 @@
+// Package dbmigrathttp provides http.Handler exposing dbmigrat migrations status
+// (e.g. for mounting under admin routes of a service).
+package dbmigrathttp
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"sync"
+
+	"github.com/graaphscom/monogo/dbmigrat"
+)
+
+// NewHandler returns handler serving following routes (relative to path under which handler is mounted,
+// use http.StripPrefix for mounting it under a prefix):
+//
+//	GET  /           HTML page with status and integrity check result
+//	GET  /status     dbmigrat.Status as JSON
+//	GET  /integrity  dbmigrat.IntegrityCheckResult as JSON
+//	GET  /ready      readiness probe, responds with 503 when dbmigrat.CheckReadyWithOptions fails
+//	POST /migrate    applies pending migrations, available only when HandlerOptions.Authorize is set
+//
+// Routes are described by Openapi.
+func NewHandler(store dbmigrat.Store, migrations dbmigrat.Migrations, repoOrder dbmigrat.RepoOrder, opts HandlerOptions) http.Handler {
+	h := &handler{store: store, migrations: migrations, repoOrder: repoOrder, opts: opts}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", h.page)
+	mux.HandleFunc("/status", h.status)
+	mux.HandleFunc("/integrity", h.integrity)
+	mux.HandleFunc("/ready", h.ready)
+	mux.HandleFunc("/migrate", h.migrate)
+	return mux
+}
+
+// HandlerOptions allows for configuring NewHandler.
+type HandlerOptions struct {
+	// Authorize guards POST /migrate. When nil, POST /migrate responds with 404.
+	Authorize func(r *http.Request) bool
+	// MigrateOptions are passed to dbmigrat.MigrateWithOptions by POST /migrate.
+	// Its Environment and Flags are passed to dbmigrat.CheckReadyWithOptions by GET /ready.
+	MigrateOptions dbmigrat.MigrateOptions
+}
+
+type handler struct {
+	store      dbmigrat.Store
+	migrations dbmigrat.Migrations
+	repoOrder  dbmigrat.RepoOrder
+	opts       HandlerOptions
+	// mu serializes access to store, which keeps transaction of running Migrate.
+	mu sync.Mutex
+}
+
+func (h *handler) page(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	h.mu.Lock()
+	status, err := dbmigrat.FetchStatus(h.store, h.migrations, h.repoOrder)
+	var integrity *dbmigrat.IntegrityCheckResult
+	if err == nil {
+		integrity, err = dbmigrat.CheckLogTableIntegrity(h.store, h.migrations)
+	}
+	h.mu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	statusTable, err := status.Table().Render()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	integrityTable, err := integrity.Table().Render()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err = pageTemplate.Execute(w, struct {
+		Status      string
+		IsCorrupted bool
+		Integrity   string
+	}{Status: statusTable, IsCorrupted: integrity.IsCorrupted, Integrity: integrityTable})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *handler) status(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	h.mu.Lock()
+	status, err := dbmigrat.FetchStatus(h.store, h.migrations, h.repoOrder)
+	h.mu.Unlock()
+	writeJSON(w, status, err)
+}
+
+func (h *handler) integrity(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	h.mu.Lock()
+	result, err := dbmigrat.CheckLogTableIntegrity(h.store, h.migrations)
+	h.mu.Unlock()
+	writeJSON(w, result, err)
+}
+
+func (h *handler) ready(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	h.mu.Lock()
+	err := dbmigrat.CheckReadyWithOptions(h.store, h.migrations, h.repoOrder, dbmigrat.ReadyOptions{
+		Environment: h.opts.MigrateOptions.Environment,
+		Flags:       h.opts.MigrateOptions.Flags,
+	})
+	h.mu.Unlock()
+	var notReady *dbmigrat.NotReadyError
+	if errors.As(err, &notReady) {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	writeJSON(w, ReadyResult{Ready: true}, err)
+}
+
+func (h *handler) migrate(w http.ResponseWriter, r *http.Request) {
+	if h.opts.Authorize == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	if !h.opts.Authorize(r) {
+		writeError(w, http.StatusForbidden, errForbidden)
+		return
+	}
+	h.mu.Lock()
+	count, err := dbmigrat.MigrateWithOptions(h.store, h.migrations, h.repoOrder, h.opts.MigrateOptions)
+	h.mu.Unlock()
+	writeJSON(w, MigrateResult{Applied: count}, err)
+}
+
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method {
+		return true
+	}
+	w.Header().Set("Allow", method)
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, body interface{}, err error) {
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(ErrorResult{Error: err.Error()})
+}
+
+// MigrateResult is returned by POST /migrate.
+type MigrateResult struct {
+	Applied int `json:"applied"`
+}
+
+// ReadyResult is returned by GET /ready when database is ready.
+type ReadyResult struct {
+	Ready bool `json:"ready"`
+}
+
+// ErrorResult is returned by every route on failure.
+type ErrorResult struct {
+	Error string `json:"error"`
+}
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>dbmigrat</title></head>
+<body>
+<h1>Migrations</h1>
+<pre>{{.Status}}</pre>
+<h1>Integrity{{if .IsCorrupted}} (corrupted){{end}}</h1>
+<pre>{{.Integrity}}</pre>
+</body>
+</html>
+`))
+
+var errForbidden = errors.New("not authorized to run migrations")
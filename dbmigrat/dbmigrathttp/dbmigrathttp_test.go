@@ -0,0 +1,136 @@
+package dbmigrathttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/graaphscom/monogo/compoas"
+	"github.com/graaphscom/monogo/dbmigrat"
+	"github.com/graaphscom/monogo/dbmigrat/dbmigrattest"
+	"github.com/stretchr/testify/assert"
+)
+
+var migrations = dbmigrat.Migrations{
+	"auth": {
+		{Up: `create table users (id serial primary key)`, Down: `drop table users`, Description: "create user table"},
+		{Up: `alter table users add column username varchar(32)`, Down: `alter table users drop column username`, Description: "add username column"},
+	},
+}
+
+func TestHandler(t *testing.T) {
+	fixture := dbmigrattest.New(t, os.Getenv("DBMIGRAT_TEST_DB_URL"), dbmigrat.Migrations{"auth": migrations["auth"][:1]}, dbmigrat.RepoOrder{"auth"})
+	handler := NewHandler(fixture.Store, migrations, dbmigrat.RepoOrder{"auth"}, HandlerOptions{
+		Authorize: func(r *http.Request) bool { return r.Header.Get("Authorization") == "Bearer secret" },
+	})
+
+	t.Run("status", func(t *testing.T) {
+		response := serve(handler, http.MethodGet, "/status", "")
+		assert.Equal(t, http.StatusOK, response.Code)
+		var status dbmigrat.Status
+		assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &status))
+		assert.Len(t, status.Repos, 1)
+		assert.Len(t, status.Repos[0].Applied, 1)
+		assert.Equal(t, []dbmigrat.PendingMigration{{Idx: 1, Description: "add username column"}}, status.Repos[0].Pending)
+	})
+
+	t.Run("integrity", func(t *testing.T) {
+		response := serve(handler, http.MethodGet, "/integrity", "")
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Contains(t, response.Body.String(), `"isCorrupted":false`)
+	})
+
+	t.Run("page", func(t *testing.T) {
+		response := serve(handler, http.MethodGet, "/", "")
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "text/html; charset=utf-8", response.Header().Get("Content-Type"))
+		assert.Contains(t, response.Body.String(), "add username column")
+	})
+
+	t.Run("not ready", func(t *testing.T) {
+		response := serve(handler, http.MethodGet, "/ready", "")
+		assert.Equal(t, http.StatusServiceUnavailable, response.Code)
+		assert.JSONEq(t, `{"error": "database is not ready: 1 pending migrations in auth"}`, response.Body.String())
+	})
+
+	t.Run("migrate", func(t *testing.T) {
+		response := serve(handler, http.MethodPost, "/migrate", "")
+		assert.Equal(t, http.StatusForbidden, response.Code)
+
+		response = serve(handler, http.MethodPost, "/migrate", "Bearer secret")
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `{"applied": 1}`, response.Body.String())
+
+		response = serve(handler, http.MethodPost, "/migrate", "Bearer secret")
+		assert.JSONEq(t, `{"applied": 0}`, response.Body.String())
+	})
+
+	t.Run("ready", func(t *testing.T) {
+		response := serve(handler, http.MethodGet, "/ready", "")
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `{"ready": true}`, response.Body.String())
+	})
+}
+
+func TestHandlerRoutes(t *testing.T) {
+	handler := NewHandler(&dbmigrat.PostgresStore{}, migrations, dbmigrat.RepoOrder{"auth"}, HandlerOptions{})
+
+	assert.Equal(t, http.StatusNotFound, serve(handler, http.MethodPost, "/migrate", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(handler, http.MethodGet, "/unknown", "").Code)
+	response := serve(handler, http.MethodPost, "/status", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, response.Code)
+	assert.Equal(t, http.MethodGet, response.Header().Get("Allow"))
+}
+
+func TestHandlerMemoryStore(t *testing.T) {
+	handler := NewHandler(&dbmigrat.MemoryStore{}, migrations, dbmigrat.RepoOrder{"auth"}, HandlerOptions{
+		Authorize: func(*http.Request) bool { return true },
+	})
+
+	response := serve(handler, http.MethodGet, "/ready", "")
+	assert.Equal(t, http.StatusServiceUnavailable, response.Code)
+	response = serve(handler, http.MethodPost, "/migrate", "")
+	assert.JSONEq(t, `{"applied": 2}`, response.Body.String())
+	response = serve(handler, http.MethodGet, "/ready", "")
+	assert.Equal(t, http.StatusOK, response.Code)
+}
+
+func TestOpenapi(t *testing.T) {
+	oas := Openapi("/admin/migrations")
+	var paths []string
+	for path := range oas.Paths {
+		paths = append(paths, path)
+	}
+	assert.ElementsMatch(t, []string{"/admin/migrations/", "/admin/migrations/status", "/admin/migrations/integrity", "/admin/migrations/ready", "/admin/migrations/migrate"}, paths)
+	assert.NotNil(t, oas.Paths["/admin/migrations/migrate"].Post)
+
+	for _, pathItem := range oas.Paths {
+		for _, operation := range []*compoas.Operation{pathItem.Get, pathItem.Post} {
+			if operation == nil {
+				continue
+			}
+			for _, response := range operation.Responses {
+				for _, mediaType := range response.Content {
+					if mediaType.Schema.Ref == "" {
+						continue
+					}
+					ref := strings.TrimPrefix(mediaType.Schema.Ref, "#/components/schemas/")
+					assert.Contains(t, oas.Components.Schemas, ref)
+				}
+			}
+		}
+	}
+}
+
+func serve(handler http.Handler, method string, target string, authorization string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, target, nil)
+	if authorization != "" {
+		request.Header.Set("Authorization", authorization)
+	}
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	return response
+}
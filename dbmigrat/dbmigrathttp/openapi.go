@@ -0,0 +1,123 @@
+package dbmigrathttp
+
+import "github.com/graaphscom/monogo/compoas"
+
+// Openapi describes routes served by NewHandler mounted under prefix (e.g. "/admin/migrations").
+// It can be merged into service's specification (see compoas.OAS.Merge) or served through compoas.UIHandler.
+func Openapi(prefix string) compoas.OAS {
+	jsonResponse := func(description string, schema string) compoas.Response {
+		return compoas.Response{
+			Description: description,
+			Content: map[string]compoas.MediaType{
+				"application/json": {Schema: &compoas.Schema{Ref: "#/components/schemas/" + schema}},
+			},
+		}
+	}
+	errorResponse := jsonResponse("Error", "DbmigratError")
+
+	return compoas.OAS{
+		Openapi: "3.0.0",
+		Info: compoas.Info{
+			Title:   "dbmigrat API",
+			Version: "1.0.0",
+		},
+		Components: &compoas.Components{
+			Schemas: map[string]compoas.Schema{
+				"DbmigratMigrationLog": {Type: "object", Properties: map[string]compoas.Schema{
+					"idx":             {Type: "integer"},
+					"repo":            {Type: "string"},
+					"migrationSerial": {Type: "integer"},
+					"checksum":        {Type: "string"},
+					"appliedAt":       {Type: "string", Format: "date-time"},
+					"description":     {Type: "string"},
+					"durationMs":      {Type: "integer"},
+					"rowsAffected":    {Type: "integer", Nullable: true},
+				}},
+				"DbmigratPendingMigration": {Type: "object", Properties: map[string]compoas.Schema{
+					"idx":         {Type: "integer"},
+					"description": {Type: "string"},
+				}},
+				"DbmigratStatus": {Type: "object", Properties: map[string]compoas.Schema{
+					"repos": {Type: "array", Items: &compoas.Schema{Type: "object", Properties: map[string]compoas.Schema{
+						"repo":    {Type: "string"},
+						"applied": {Type: "array", Items: &compoas.Schema{Ref: "#/components/schemas/DbmigratMigrationLog"}},
+						"pending": {Type: "array", Items: &compoas.Schema{Ref: "#/components/schemas/DbmigratPendingMigration"}},
+					}}},
+				}},
+				"DbmigratIntegrityCheckResult": {Type: "object", Properties: map[string]compoas.Schema{
+					"isCorrupted":           {Type: "boolean"},
+					"redundantRepos":        {Type: "object", Description: "repo name to true"},
+					"redundantMigrations":   {Type: "object", Description: "repo name to array of DbmigratMigrationLog"},
+					"invalidChecksums":      {Type: "object", Description: "repo name to array of DbmigratMigrationLog"},
+					"conflictingMigrations": {Type: "object", Description: "repo name to array of DbmigratMigrationLog"},
+					"gaps":                  {Type: "object", Description: "repo name to array of DbmigratPendingMigration"},
+				}},
+				"DbmigratMigrateResult": {Type: "object", Properties: map[string]compoas.Schema{
+					"applied": {Type: "integer"},
+				}},
+				"DbmigratReadyResult": {Type: "object", Properties: map[string]compoas.Schema{
+					"ready": {Type: "boolean"},
+				}},
+				"DbmigratError": {Type: "object", Properties: map[string]compoas.Schema{
+					"error": {Type: "string"},
+				}},
+			},
+		},
+		Paths: map[string]compoas.PathItem{
+			prefix + "/": {
+				Get: &compoas.Operation{
+					Tags: []string{"dbmigrat"},
+					Responses: map[string]compoas.Response{
+						"200": {
+							Description: "HTML page with status and integrity check result",
+							Content:     map[string]compoas.MediaType{"text/html": {Schema: &compoas.Schema{Type: "string"}}},
+						},
+						"500": {
+							Description: "Error",
+							Content:     map[string]compoas.MediaType{"text/plain": {Schema: &compoas.Schema{Type: "string"}}},
+						},
+					},
+				},
+			},
+			prefix + "/status": {
+				Get: &compoas.Operation{
+					Tags: []string{"dbmigrat"},
+					Responses: map[string]compoas.Response{
+						"200": jsonResponse("Applied and pending migrations", "DbmigratStatus"),
+						"500": errorResponse,
+					},
+				},
+			},
+			prefix + "/integrity": {
+				Get: &compoas.Operation{
+					Tags: []string{"dbmigrat"},
+					Responses: map[string]compoas.Response{
+						"200": jsonResponse("Integrity check result", "DbmigratIntegrityCheckResult"),
+						"500": errorResponse,
+					},
+				},
+			},
+			prefix + "/ready": {
+				Get: &compoas.Operation{
+					Tags: []string{"dbmigrat"},
+					Responses: map[string]compoas.Response{
+						"200": jsonResponse("Database has no pending migrations and its migrations log is not corrupted", "DbmigratReadyResult"),
+						"500": errorResponse,
+						"503": errorResponse,
+					},
+				},
+			},
+			prefix + "/migrate": {
+				Post: &compoas.Operation{
+					Tags: []string{"dbmigrat"},
+					Responses: map[string]compoas.Response{
+						"200": jsonResponse("Count of applied migrations", "DbmigratMigrateResult"),
+						"403": errorResponse,
+						"404": {Description: "Migrating is disabled"},
+						"500": errorResponse,
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,214 @@
+package dbmigrat
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/graaphscom/monogo/asciiui"
+)
+
+// DiffMigrations compares migrations from base (e.g. main branch) with migrations from head (e.g. pull request)
+// and returns their differences per repo:
+//   - renumbered migration has the same description and up SQL, but different index,
+//   - modified migration has the same index and description, but different SQL,
+//   - other migrations present only in base are removed, present only in head are added.
+//
+// Changes of other Migration fields (e.g. Tags) are not reported.
+func DiffMigrations(base Migrations, head Migrations) MigrationsDiff {
+	repos := map[Repo]bool{}
+	for repo := range base {
+		repos[repo] = true
+	}
+	for repo := range head {
+		repos[repo] = true
+	}
+
+	diff := MigrationsDiff{Repos: []RepoDiff{}}
+	for _, repo := range sortedRepos(repos) {
+		changes := diffRepo(base[repo], head[repo])
+		if len(changes) > 0 {
+			diff.Repos = append(diff.Repos, RepoDiff{Repo: repo, Changes: changes})
+		}
+	}
+	return diff
+}
+
+func diffRepo(base []Migration, head []Migration) []MigrationChange {
+	matchedBase := make([]bool, len(base))
+	matchedHead := make([]bool, len(head))
+	for idx := 0; idx < len(base) && idx < len(head); idx++ {
+		if base[idx].Description == head[idx].Description && base[idx].Up == head[idx].Up && base[idx].Down == head[idx].Down {
+			matchedBase[idx] = true
+			matchedHead[idx] = true
+		}
+	}
+
+	var changes []MigrationChange
+	for baseIdx, baseMigration := range base {
+		if matchedBase[baseIdx] {
+			continue
+		}
+		for headIdx, headMigration := range head {
+			if matchedHead[headIdx] || headIdx == baseIdx || baseMigration.Description != headMigration.Description || baseMigration.Up != headMigration.Up {
+				continue
+			}
+			matchedBase[baseIdx] = true
+			matchedHead[headIdx] = true
+			changes = append(changes, MigrationChange{
+				Kind:        ChangeRenumbered,
+				BaseIdx:     baseIdx,
+				HeadIdx:     headIdx,
+				Description: headMigration.Description,
+				DownDiff:    diffLines(baseMigration.Down, headMigration.Down),
+			})
+			break
+		}
+	}
+	for idx := 0; idx < len(base) && idx < len(head); idx++ {
+		if matchedBase[idx] || matchedHead[idx] || base[idx].Description != head[idx].Description {
+			continue
+		}
+		matchedBase[idx] = true
+		matchedHead[idx] = true
+		changes = append(changes, MigrationChange{
+			Kind:        ChangeModified,
+			BaseIdx:     idx,
+			HeadIdx:     idx,
+			Description: head[idx].Description,
+			UpDiff:      diffLines(base[idx].Up, head[idx].Up),
+			DownDiff:    diffLines(base[idx].Down, head[idx].Down),
+		})
+	}
+	for idx, migration := range base {
+		if !matchedBase[idx] {
+			changes = append(changes, MigrationChange{Kind: ChangeRemoved, BaseIdx: idx, HeadIdx: -1, Description: migration.Description})
+		}
+	}
+	for idx, migration := range head {
+		if !matchedHead[idx] {
+			changes = append(changes, MigrationChange{Kind: ChangeAdded, BaseIdx: -1, HeadIdx: idx, Description: migration.Description})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].position() < changes[j].position() })
+	return changes
+}
+
+// diffLines returns line based diff of base and head texts. Every line is prefixed
+// with "-" (present only in base), "+" (present only in head) or " " (present in both).
+// It returns empty string when texts are equal.
+func diffLines(base string, head string) string {
+	if base == head {
+		return ""
+	}
+	baseLines := strings.Split(base, "\n")
+	headLines := strings.Split(head, "\n")
+
+	// lcs[i][j] is length of the longest common subsequence of baseLines[i:] and headLines[j:]
+	lcs := make([][]int, len(baseLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(headLines)+1)
+	}
+	for i := len(baseLines) - 1; i >= 0; i-- {
+		for j := len(headLines) - 1; j >= 0; j-- {
+			switch {
+			case baseLines[i] == headLines[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var builder strings.Builder
+	i, j := 0, 0
+	for i < len(baseLines) || j < len(headLines) {
+		switch {
+		case i < len(baseLines) && j < len(headLines) && baseLines[i] == headLines[j]:
+			builder.WriteString(" " + baseLines[i] + "\n")
+			i++
+			j++
+		case j == len(headLines) || (i < len(baseLines) && lcs[i+1][j] >= lcs[i][j+1]):
+			builder.WriteString("-" + baseLines[i] + "\n")
+			i++
+		default:
+			builder.WriteString("+" + headLines[j] + "\n")
+			j++
+		}
+	}
+	return builder.String()
+}
+
+// ChangesExisting reports whether any migration from base was changed (e.g. released migration was edited).
+// Adding new migrations after existing ones is not treated as a change.
+func (d MigrationsDiff) ChangesExisting() bool {
+	for _, repo := range d.Repos {
+		for _, change := range repo.Changes {
+			if change.Kind != ChangeAdded {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Table renders diff as asciiui.Table with one row per changed migration (without SQL diffs).
+func (d MigrationsDiff) Table() asciiui.Table {
+	table := asciiui.Table{Rows: []asciiui.TableRow{
+		tableRow("Repo", "Change", "Base idx", "Head idx", "Description"),
+	}}
+	formatIdx := func(idx int) string {
+		if idx < 0 {
+			return ""
+		}
+		return strconv.Itoa(idx)
+	}
+	for _, repo := range d.Repos {
+		for _, change := range repo.Changes {
+			table.Rows = append(table.Rows, tableRow(string(repo.Repo), string(change.Kind), formatIdx(change.BaseIdx), formatIdx(change.HeadIdx), change.Description))
+		}
+	}
+	return table
+}
+
+// MigrationsDiff is returned by DiffMigrations. It contains only repos with changes, sorted by name.
+type MigrationsDiff struct {
+	Repos []RepoDiff `json:"repos"`
+}
+
+// RepoDiff describes changed migrations of single repo.
+type RepoDiff struct {
+	Repo    Repo              `json:"repo"`
+	Changes []MigrationChange `json:"changes"`
+}
+
+// MigrationChange describes single changed migration.
+// BaseIdx is -1 for added migration, HeadIdx is -1 for removed migration.
+// UpDiff and DownDiff contain diff of SQL (see diffLines) or are empty when SQL was not changed.
+type MigrationChange struct {
+	Kind        ChangeKind `json:"kind"`
+	BaseIdx     int        `json:"baseIdx"`
+	HeadIdx     int        `json:"headIdx"`
+	Description string     `json:"description"`
+	UpDiff      string     `json:"upDiff,omitempty"`
+	DownDiff    string     `json:"downDiff,omitempty"`
+}
+
+func (c MigrationChange) position() int {
+	if c.BaseIdx < 0 {
+		return c.HeadIdx
+	}
+	return c.BaseIdx
+}
+
+type ChangeKind string
+
+const (
+	ChangeAdded      ChangeKind = "added"
+	ChangeRemoved    ChangeKind = "removed"
+	ChangeModified   ChangeKind = "modified"
+	ChangeRenumbered ChangeKind = "renumbered"
+)
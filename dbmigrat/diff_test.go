@@ -0,0 +1,73 @@
+package dbmigrat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffMigrations(t *testing.T) {
+	base := Migrations{
+		"auth": {
+			{Description: "create users", Up: "create table users (\n  id serial primary key\n)", Down: "drop table users"},
+			{Description: "add username", Up: "alter table users add column username text", Down: "alter table users drop column username"},
+			{Description: "add email", Up: "alter table users add column email text", Down: "alter table users drop column email"},
+		},
+		"billing": {
+			{Description: "create orders", Up: "create table orders (id serial primary key)", Down: "drop table orders"},
+		},
+		"legacy": {
+			{Description: "create legacy", Up: "create table legacy ()", Down: "drop table legacy"},
+		},
+	}
+	head := Migrations{
+		"auth": {
+			{Description: "create users", Up: "create table users (\n  id bigserial primary key\n)", Down: "drop table users", Tags: []string{"v1"}},
+			{Description: "add email", Up: "alter table users add column email text", Down: "alter table users drop column email"},
+			{Description: "add phone", Up: "alter table users add column phone text", Down: "alter table users drop column phone"},
+		},
+		"billing": {
+			{Description: "create orders", Up: "create table orders (id serial primary key)", Down: "drop table orders"},
+			{Description: "add value", Up: "alter table orders add column value numeric", Down: "alter table orders drop column value"},
+		},
+		"legacy": base["legacy"],
+	}
+
+	diff := DiffMigrations(base, head)
+	assert.Equal(t, MigrationsDiff{Repos: []RepoDiff{
+		{Repo: "auth", Changes: []MigrationChange{
+			{Kind: ChangeModified, BaseIdx: 0, HeadIdx: 0, Description: "create users", UpDiff: " create table users (\n-  id serial primary key\n+  id bigserial primary key\n )\n"},
+			{Kind: ChangeRemoved, BaseIdx: 1, HeadIdx: -1, Description: "add username"},
+			{Kind: ChangeRenumbered, BaseIdx: 2, HeadIdx: 1, Description: "add email"},
+			{Kind: ChangeAdded, BaseIdx: -1, HeadIdx: 2, Description: "add phone"},
+		}},
+		{Repo: "billing", Changes: []MigrationChange{
+			{Kind: ChangeAdded, BaseIdx: -1, HeadIdx: 1, Description: "add value"},
+		}},
+	}}, diff)
+	assert.True(t, diff.ChangesExisting())
+	assert.False(t, DiffMigrations(Migrations{"billing": base["billing"]}, Migrations{"billing": head["billing"]}).ChangesExisting())
+	assert.Equal(t, MigrationsDiff{Repos: []RepoDiff{}}, DiffMigrations(base, base))
+
+	rendered, err := diff.Table().Render()
+	assert.NoError(t, err)
+	assert.Equal(t, `+---------+------------+----------+----------+--------------+
+| Repo    | Change     | Base idx | Head idx | Description  |
++---------+------------+----------+----------+--------------+
+| auth    | modified   | 0        | 0        | create users |
++---------+------------+----------+----------+--------------+
+| auth    | removed    | 1        |          | add username |
++---------+------------+----------+----------+--------------+
+| auth    | renumbered | 2        | 1        | add email    |
++---------+------------+----------+----------+--------------+
+| auth    | added      |          | 2        | add phone    |
++---------+------------+----------+----------+--------------+
+| billing | added      |          | 1        | add value    |
++---------+------------+----------+----------+--------------+
+`, rendered)
+}
+
+func TestDiffLines(t *testing.T) {
+	assert.Equal(t, "", diffLines("a\nb", "a\nb"))
+	assert.Equal(t, "-a\n b\n+c\n", diffLines("a\nb", "b\nc"))
+}
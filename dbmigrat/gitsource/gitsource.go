@@ -0,0 +1,67 @@
+// Package gitsource allows for reading migrations from history of git repository (e.g. of a previous release)
+// without git binary. It is separated from dbmigrat package, so users not reading git history do not depend on go-git.
+package gitsource
+
+import (
+	"io/fs"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/graaphscom/monogo/dbmigrat/internal/memfs"
+)
+
+// TreeFS reads tree of git repository containing repoPath into memory and returns its file system,
+// which can be passed to dbmigrat.ReadDir, dbmigrat.ReadManifest etc.
+// Paths in returned file system are relative to the root of repository (see WorktreeRoot).
+// revision is anything which resolves to commit, e.g. "v1.2.0", "main~3" or commit hash.
+func TreeFS(repoPath string, revision string) (fs.FS, error) {
+	repository, err := open(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := repository.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, err
+	}
+	commit, err := repository.CommitObject(*hash)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	fileSys := memfs.FS{}
+	err = tree.Files().ForEach(func(file *object.File) error {
+		contents, err := file.Contents()
+		if err != nil {
+			return err
+		}
+		fileSys[file.Name] = &memfs.File{Data: []byte(contents), ModTime: commit.Committer.When}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fileSys, nil
+}
+
+// WorktreeRoot returns root directory of git repository containing repoPath.
+func WorktreeRoot(repoPath string) (string, error) {
+	repository, err := open(repoPath)
+	if err != nil {
+		return "", err
+	}
+	worktree, err := repository.Worktree()
+	if err != nil {
+		return "", err
+	}
+	return worktree.Filesystem.Root(), nil
+}
+
+func open(repoPath string) (*git.Repository, error) {
+	return git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{DetectDotGit: true})
+}
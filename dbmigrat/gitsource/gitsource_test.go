@@ -0,0 +1,64 @@
+package gitsource
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/graaphscom/monogo/dbmigrat"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTreeFS(t *testing.T) {
+	dir := t.TempDir()
+	repository, err := git.PlainInit(dir, false)
+	assert.NoError(t, err)
+	worktree, err := repository.Worktree()
+	assert.NoError(t, err)
+	commit := func(files map[string]string) {
+		for name, content := range files {
+			assert.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0700))
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+			_, err := worktree.Add(name)
+			assert.NoError(t, err)
+		}
+		_, err := worktree.Commit("add migrations", &git.CommitOptions{
+			Author: &object.Signature{Name: "dbmigrat", Email: "dbmigrat@example.com", When: time.Now()},
+		})
+		assert.NoError(t, err)
+	}
+	commit(map[string]string{
+		"auth/migrations/0.create_users.up.sql":   "create table users (id serial primary key)",
+		"auth/migrations/0.create_users.down.sql": "drop table users",
+	})
+	commit(map[string]string{
+		"auth/migrations/1.add_username.up.sql":   "alter table users add column username text",
+		"auth/migrations/1.add_username.down.sql": "alter table users drop column username",
+	})
+
+	fileSys, err := TreeFS(dir, "HEAD~1")
+	assert.NoError(t, err)
+	migrations, err := dbmigrat.ReadDir(fileSys, "auth/migrations")
+	assert.NoError(t, err)
+	assert.Equal(t, []dbmigrat.Migration{
+		{Description: "create_users", Up: "create table users (id serial primary key)", Down: "drop table users"},
+	}, migrations)
+
+	fileSys, err = TreeFS(filepath.Join(dir, "auth"), "HEAD")
+	assert.NoError(t, err)
+	migrations, err = dbmigrat.ReadDir(fileSys, "auth/migrations")
+	assert.NoError(t, err)
+	assert.Len(t, migrations, 2)
+
+	root, err := WorktreeRoot(filepath.Join(dir, "auth", "migrations"))
+	assert.NoError(t, err)
+	assert.Equal(t, dir, root)
+
+	t.Run("returns error for unknown revision", func(t *testing.T) {
+		_, err := TreeFS(dir, "v0.0.0")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,159 @@
+package dbmigrat
+
+import (
+	"errors"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// FetchHistory returns every apply and rollback of migrations (including failed ones)
+// in order they happened. Unlike migrations log, history is never deleted by Rollback.
+func FetchHistory(s store) ([]HistoryEntry, error) {
+	return s.fetchHistory()
+}
+
+func insertLogs(s store, logs []MigrationLog, actor string) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	err := s.insertLogs(logs)
+	if err != nil {
+		return err
+	}
+	return s.insertHistory(newHistoryEntries(logs, HistoryApply, actor))
+}
+
+func deleteLogs(s store, logs []MigrationLog, actor string) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	err := s.deleteLogs(logs)
+	if err != nil {
+		return err
+	}
+	return s.insertHistory(newHistoryEntries(logs, HistoryRollback, actor))
+}
+
+// execMigration executes migration's query and saves its duration and affected rows count in log.
+// Returned error allows for saving failure in history after rolling back transaction (see insertFailureHistory).
+func execMigration(s store, query string, log *MigrationLog, action HistoryAction, actor string) error {
+	start := time.Now()
+	rowsAffected, err := s.exec(query)
+	if err != nil {
+		entry := newHistoryEntries([]MigrationLog{*log}, action, actor)[0]
+		entry.Outcome = HistoryFailure
+		entry.Error = err.Error()
+		return &execMigrationError{entry: entry, err: err}
+	}
+	log.DurationMs = time.Since(start).Milliseconds()
+	if isDML(query) {
+		log.RowsAffected = &rowsAffected
+	}
+	return nil
+}
+
+// isDML reports whether the last statement of query modifies rows (its affected rows count is meaningful).
+func isDML(query string) bool {
+	statements := splitStatements(query)
+	if len(statements) == 0 {
+		return false
+	}
+	command := strings.SplitN(statements[len(statements)-1], " ", 2)[0]
+	return command == "insert" || command == "update" || command == "delete" || command == "merge" || command == "copy"
+}
+
+// insertFailureHistory saves failed migration in history. It must be called
+// when transaction is already rolled back, otherwise saved entry would be rolled back too.
+func insertFailureHistory(s store, err error, actor string) error {
+	var execErr *execMigrationError
+	if !errors.As(err, &execErr) {
+		return err
+	}
+	historyErr := s.insertHistory([]HistoryEntry{execErr.entry})
+	if historyErr != nil {
+		return multierror.Append(err, historyErr)
+	}
+	return err
+}
+
+func newHistoryEntries(logs []MigrationLog, action HistoryAction, actor string) []HistoryEntry {
+	if actor == "" {
+		actor = currentUserName()
+	}
+	host, _ := os.Hostname()
+
+	entries := make([]HistoryEntry, 0, len(logs))
+	for _, log := range logs {
+		entries = append(entries, HistoryEntry{
+			Idx:             log.Idx,
+			Repo:            log.Repo,
+			MigrationSerial: log.MigrationSerial,
+			Checksum:        log.Checksum,
+			Description:     log.Description,
+			Action:          action,
+			Outcome:         HistorySuccess,
+			Actor:           actor,
+			Host:            host,
+		})
+	}
+	return entries
+}
+
+func currentUserName() string {
+	currentUser, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	return currentUser.Username
+}
+
+// HistoryEntry represents single apply or rollback of migration.
+// Error contains error returned by database when Outcome is HistoryFailure.
+// Entries of repeatable migrations have Idx -1 and migration name as Description.
+type HistoryEntry struct {
+	ID              int            `json:"id"`
+	Idx             int            `json:"idx"`
+	Repo            Repo           `json:"repo"`
+	MigrationSerial int            `db:"migration_serial" json:"migrationSerial"`
+	Checksum        string         `json:"checksum"`
+	Description     string         `json:"description"`
+	Action          HistoryAction  `json:"action"`
+	Outcome         HistoryOutcome `json:"outcome"`
+	Error           string         `json:"error,omitempty"`
+	Actor           string         `json:"actor"`
+	Host            string         `json:"host"`
+	RecordedAt      time.Time      `db:"recorded_at" json:"recordedAt"`
+}
+
+type HistoryAction string
+
+const (
+	HistoryApply    HistoryAction = "apply"
+	HistoryRollback HistoryAction = "rollback"
+	// HistoryImport marks logs saved by ImportGolangMigrate, ImportGoose and ImportFlyway.
+	HistoryImport HistoryAction = "import"
+)
+
+type HistoryOutcome string
+
+const (
+	HistorySuccess HistoryOutcome = "success"
+	HistoryFailure HistoryOutcome = "failure"
+)
+
+type execMigrationError struct {
+	entry HistoryEntry
+	err   error
+}
+
+func (e *execMigrationError) Error() string {
+	return e.err.Error()
+}
+
+func (e *execMigrationError) Unwrap() error {
+	return e.err
+}
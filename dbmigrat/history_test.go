@@ -0,0 +1,88 @@
+package dbmigrat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchHistory(t *testing.T) {
+	assert.NoError(t, th.resetDB())
+	assert.NoError(t, th.pgStore.CreateLogTable())
+
+	_, err := Migrate(th.pgStore, th.migrations1, RepoOrder{"auth", "billing"})
+	assert.NoError(t, err)
+	_, err = RollbackWithOptions(th.pgStore, th.migrations1, RepoOrder{"billing", "auth"}, -1, RollbackOptions{Actor: "incident-bot"})
+	assert.NoError(t, err)
+	brokenMigrations := Migrations{"auth": {{Up: `create table`, Description: "broken"}}}
+	_, err = MigrateWithOptions(th.pgStore, brokenMigrations, RepoOrder{"auth"}, MigrateOptions{Actor: "deploy-bot"})
+	assert.Error(t, err)
+
+	history, err := FetchHistory(th.pgStore)
+	assert.NoError(t, err)
+	type entry struct {
+		Idx             int
+		Repo            Repo
+		MigrationSerial int
+		Action          HistoryAction
+		Outcome         HistoryOutcome
+	}
+	var entries []entry
+	for _, historyEntry := range history {
+		entries = append(entries, entry{
+			Idx:             historyEntry.Idx,
+			Repo:            historyEntry.Repo,
+			MigrationSerial: historyEntry.MigrationSerial,
+			Action:          historyEntry.Action,
+			Outcome:         historyEntry.Outcome,
+		})
+	}
+	assert.Equal(t, []entry{
+		{Idx: 0, Repo: "auth", MigrationSerial: 0, Action: HistoryApply, Outcome: HistorySuccess},
+		{Idx: 1, Repo: "auth", MigrationSerial: 0, Action: HistoryApply, Outcome: HistorySuccess},
+		{Idx: 0, Repo: "billing", MigrationSerial: 0, Action: HistoryApply, Outcome: HistorySuccess},
+		{Idx: 0, Repo: "billing", MigrationSerial: 0, Action: HistoryRollback, Outcome: HistorySuccess},
+		{Idx: 1, Repo: "auth", MigrationSerial: 0, Action: HistoryRollback, Outcome: HistorySuccess},
+		{Idx: 0, Repo: "auth", MigrationSerial: 0, Action: HistoryRollback, Outcome: HistorySuccess},
+		{Idx: 0, Repo: "auth", MigrationSerial: 0, Action: HistoryApply, Outcome: HistoryFailure},
+	}, entries)
+	assert.Equal(t, "incident-bot", history[3].Actor)
+	assert.Equal(t, "deploy-bot", history[6].Actor)
+	assert.Equal(t, sha1Checksum(`create table`), history[6].Checksum)
+	assert.NotEmpty(t, history[6].Error)
+
+	t.Run("db error", func(t *testing.T) {
+		res, err := FetchHistory(errorStoreMock{wrapped: th.pgStore, errFetchHistory: true})
+		assert.EqualError(t, err, exampleErr.Error())
+		assert.Nil(t, res)
+	})
+}
+
+func TestMigrationStats(t *testing.T) {
+	assert.NoError(t, th.resetDB())
+	assert.NoError(t, th.pgStore.CreateLogTable())
+	migrations := Migrations{"auth": {
+		th.migrations1["auth"][0],
+		{Up: `insert into users default values; insert into users default values`, Down: `delete from users`, Description: "seed users"},
+		{Up: `update users set id = id + 10 -- shift ids`, Down: `update users set id = id - 10`, Description: "shift ids"},
+	}}
+
+	_, err := Migrate(th.pgStore, migrations, RepoOrder{"auth"})
+	assert.NoError(t, err)
+	status, err := FetchStatus(th.pgStore, migrations, RepoOrder{"auth"})
+	assert.NoError(t, err)
+	var rowsAffected []*int64
+	for _, log := range status.Repos[0].Applied {
+		assert.GreaterOrEqual(t, log.DurationMs, int64(0))
+		rowsAffected = append(rowsAffected, log.RowsAffected)
+	}
+	one, two := int64(1), int64(2)
+	assert.Equal(t, []*int64{nil, &one, &two}, rowsAffected)
+}
+
+func TestIsDML(t *testing.T) {
+	assert.True(t, isDML(`insert into users default values`))
+	assert.True(t, isDML(`create table users (id integer); UPDATE users set id = 1;`))
+	assert.False(t, isDML(`update users set id = 1; create index users_idx on users (id)`))
+	assert.False(t, isDML(`-- insert into users`))
+}
@@ -0,0 +1,224 @@
+package dbmigrat
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/jackc/pgx/v5"
+)
+
+// ImportGolangMigrate saves migrations applied by golang-migrate (read from schema_migrations table)
+// in migrations log of opts.Repo, so this repo can be migrated by dbmigrat from now on.
+// Every migration up to the version saved by golang-migrate is treated as applied.
+// It returns count of saved logs.
+func ImportGolangMigrate(s store, migrations Migrations, opts ImportOptions) (int, error) {
+	return importTool(s, migrations, opts, golangMigrateTool)
+}
+
+// ImportGoose saves migrations applied by goose (read from goose_db_version table)
+// in migrations log of opts.Repo (see ImportGolangMigrate).
+// Migrations rolled back by goose are not saved.
+func ImportGoose(s store, migrations Migrations, opts ImportOptions) (int, error) {
+	return importTool(s, migrations, opts, gooseTool)
+}
+
+// ImportFlyway saves versioned migrations successfully applied by Flyway (read from flyway_schema_history table)
+// in migrations log of opts.Repo (see ImportGolangMigrate).
+// Migrations up to the baseline version are treated as applied, undone migrations are not saved.
+// Flyway's repeatable migrations are skipped.
+func ImportFlyway(s store, migrations Migrations, opts ImportOptions) (int, error) {
+	return importTool(s, migrations, opts, flywayTool)
+}
+
+// ImportOptions describes how migrations applied by other tool map to repo's migrations.
+type ImportOptions struct {
+	// Repo whose migrations log is populated. It must not contain any logs yet.
+	Repo Repo
+	// Versions[i] is version under which migrations[Repo][i] is known to imported tool
+	// (e.g. "20230102150405", or "1.2" for Flyway).
+	// When empty, versions are assumed to be sequential ints starting from one (1,2,3,..).
+	Versions []string
+	// Table overrides name of table read from imported tool (e.g. "myschema.schema_migrations").
+	// Schema and table names are quoted, so they are case-sensitive.
+	Table string
+	// Actor is saved in history, defaults to OS user name.
+	Actor string
+}
+
+// table returns quoted name of table read from tool.
+func (o ImportOptions) table(tool importedTool) (quotedIdentifier, error) {
+	if o.Table == "" {
+		return quoteIdentifier(tool.defaultTable)
+	}
+	return quoteIdentifier(o.Table)
+}
+
+// importedTool describes table in which other tool saves applied migrations.
+type importedTool struct {
+	defaultTable string
+	// query returns columns of importedVersion, %s is replaced with quoted name of table.
+	query string
+}
+
+func (t importedTool) queryFrom(table quotedIdentifier) string {
+	return fmt.Sprintf(t.query, table)
+}
+
+var (
+	golangMigrateTool = importedTool{
+		defaultTable: "schema_migrations",
+		query:        `select version::text, true, true, dirty from %s`,
+	}
+	gooseTool = importedTool{
+		defaultTable: "goose_db_version",
+		query:        `select version_id::text, is_applied, false, false from %s where version_id <> 0 order by id`,
+	}
+	flywayTool = importedTool{
+		defaultTable: "flyway_schema_history",
+		query:        `select version, type not like 'UNDO%%', type = 'BASELINE', false from %s where version is not null and success order by installed_rank`,
+	}
+)
+
+// quotedIdentifier is name of table safe for putting into query.
+type quotedIdentifier string
+
+// quoteIdentifier quotes name of table optionally qualified by schema (e.g. "myschema.schema_migrations").
+func quoteIdentifier(name string) (quotedIdentifier, error) {
+	parts := strings.Split(name, ".")
+	if len(parts) > 2 {
+		return "", fmt.Errorf("%w (%s)", errInvalidImportTable, name)
+	}
+	for _, part := range parts {
+		if part == "" {
+			return "", fmt.Errorf("%w (%s)", errInvalidImportTable, name)
+		}
+	}
+	return quotedIdentifier(pgx.Identifier(parts).Sanitize()), nil
+}
+
+// importedVersion is single entry read from imported tool's table.
+// When baseline is true, all migrations preceding version are applied too.
+// Dirty migration was not completely applied.
+type importedVersion struct {
+	version  string
+	applied  bool
+	baseline bool
+	dirty    bool
+}
+
+// importTool saves migrations described by versions read from tool's table in migrations log.
+func importTool(s store, migrations Migrations, opts ImportOptions, tool importedTool) (int, error) {
+	repoMigrations, ok := migrations[opts.Repo]
+	if !ok {
+		return 0, fmt.Errorf("%w (repo: %s)", errUnknownImportRepo, opts.Repo)
+	}
+	table, err := opts.table(tool)
+	if err != nil {
+		return 0, err
+	}
+	versions, err := s.fetchImportedVersions(tool, table)
+	if err != nil {
+		return 0, err
+	}
+	for _, version := range versions {
+		if version.dirty {
+			return 0, fmt.Errorf("%w (version: %s)", errDirtyImport, version.version)
+		}
+	}
+	applied, err := importedIndexes(len(repoMigrations), opts.Versions, versions)
+	if err != nil {
+		return 0, err
+	}
+
+	err = s.begin()
+	if err != nil {
+		return 0, err
+	}
+	logs, err := importLogs(s, repoMigrations, opts, applied)
+	if err != nil {
+		return 0, multierror.Append(err, s.rollback())
+	}
+	return len(logs), s.commit()
+}
+
+// importedIndexes returns indexes of repo's migrations applied according to versions.
+func importedIndexes(migrationsCount int, repoVersions []string, versions []importedVersion) ([]bool, error) {
+	if len(repoVersions) == 0 {
+		repoVersions = make([]string, migrationsCount)
+		for i := range repoVersions {
+			repoVersions[i] = strconv.Itoa(i + 1)
+		}
+	}
+	if len(repoVersions) != migrationsCount {
+		return nil, errImportVersionsCount
+	}
+	indexes := make(map[string]int, len(repoVersions))
+	for idx, version := range repoVersions {
+		indexes[version] = idx
+	}
+
+	applied := make([]bool, migrationsCount)
+	for _, version := range versions {
+		idx, ok := indexes[version.version]
+		if !ok {
+			return nil, fmt.Errorf("%w (version: %s)", errUnknownImportedVersion, version.version)
+		}
+		applied[idx] = version.applied
+		if version.baseline {
+			for i := 0; i < idx; i++ {
+				applied[i] = true
+			}
+		}
+	}
+	return applied, nil
+}
+
+func importLogs(s store, repoMigrations []Migration, opts ImportOptions, applied []bool) ([]MigrationLog, error) {
+	existingLogs, err := s.fetchAllMigrationLogs()
+	if err != nil {
+		return nil, err
+	}
+	for _, log := range existingLogs {
+		if log.Repo == opts.Repo {
+			return nil, fmt.Errorf("%w (repo: %s)", errImportRepoNotEmpty, opts.Repo)
+		}
+	}
+	lastMigrationSerial, err := s.fetchLastMigrationSerial()
+	if err != nil {
+		return nil, err
+	}
+
+	var logs []MigrationLog
+	for idx, migration := range repoMigrations {
+		if !applied[idx] {
+			continue
+		}
+		logs = append(logs, MigrationLog{
+			Idx:             idx,
+			Repo:            opts.Repo,
+			MigrationSerial: lastMigrationSerial + 1,
+			Checksum:        sha1Checksum(migration.Up),
+			Description:     migration.Description,
+		})
+	}
+	if len(logs) == 0 {
+		return nil, nil
+	}
+	err = s.insertLogs(logs)
+	if err != nil {
+		return nil, err
+	}
+	return logs, s.insertHistory(newHistoryEntries(logs, HistoryImport, opts.Actor))
+}
+
+var (
+	errUnknownImportRepo      = errors.New("repo not present in migrations")
+	errImportRepoNotEmpty     = errors.New("migrations log already contains logs of imported repo")
+	errImportVersionsCount    = errors.New("count of versions must equal count of repo migrations")
+	errUnknownImportedVersion = errors.New("imported tool applied migration with version not present in versions")
+	errDirtyImport            = errors.New("imported tool left migration in dirty state, fix it before importing")
+	errInvalidImportTable     = errors.New("imported tool's table must be given as table or schema.table")
+)
@@ -0,0 +1,138 @@
+package dbmigrat
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImport(t *testing.T) {
+	migrations := Migrations{"auth": th.migrations2["auth"], "billing": th.migrations2["billing"]}
+
+	t.Run("golang-migrate", func(t *testing.T) {
+		assert.NoError(t, th.resetDB())
+		assert.NoError(t, th.pgStore.CreateLogTable())
+		_, err := th.db.Exec(`create table schema_migrations (version bigint not null primary key, dirty boolean not null);
+			insert into schema_migrations values (2, false)`)
+		assert.NoError(t, err)
+
+		count, err := ImportGolangMigrate(th.pgStore, migrations, ImportOptions{Repo: "auth"})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+		assertImportedIndexes(t, migrations, "auth", []int{0, 1})
+
+		_, err = ImportGolangMigrate(th.pgStore, migrations, ImportOptions{Repo: "auth"})
+		assert.ErrorIs(t, err, errImportRepoNotEmpty)
+
+		_, err = th.db.Exec(`update schema_migrations set dirty = true`)
+		assert.NoError(t, err)
+		_, err = ImportGolangMigrate(th.pgStore, migrations, ImportOptions{Repo: "billing"})
+		assert.ErrorIs(t, err, errDirtyImport)
+	})
+
+	t.Run("goose", func(t *testing.T) {
+		assert.NoError(t, th.resetDB())
+		assert.NoError(t, th.pgStore.CreateLogTable())
+		_, err := th.db.Exec(`create table goose_db_version (id serial primary key, version_id bigint not null, is_applied boolean not null, tstamp timestamp default now());
+			insert into goose_db_version (version_id, is_applied) values (0, true), (20230101, true), (20230102, true), (20230102, false)`)
+		assert.NoError(t, err)
+
+		count, err := ImportGoose(th.pgStore, migrations, ImportOptions{Repo: "billing", Versions: []string{"20230101", "20230102"}})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assertImportedIndexes(t, migrations, "billing", []int{0})
+	})
+
+	t.Run("flyway", func(t *testing.T) {
+		assert.NoError(t, th.resetDB())
+		assert.NoError(t, th.pgStore.CreateLogTable())
+		_, err := th.db.Exec(`create table flyway_schema_history (installed_rank integer primary key, version varchar(50), type varchar(20) not null, success boolean not null);
+			insert into flyway_schema_history values (1, '1', 'BASELINE', true), (2, '1.1', 'SQL', false), (3, null, 'SQL', true)`)
+		assert.NoError(t, err)
+
+		count, err := ImportFlyway(th.pgStore, migrations, ImportOptions{Repo: "auth", Versions: []string{"1", "1.1"}})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assertImportedIndexes(t, migrations, "auth", []int{0})
+
+		history, err := FetchHistory(th.pgStore)
+		assert.NoError(t, err)
+		assert.Equal(t, HistoryImport, history[0].Action)
+	})
+
+	t.Run("pgx store", func(t *testing.T) {
+		assert.NoError(t, th.resetDB())
+		pool, err := pgxpool.New(context.Background(), os.Getenv("DBMIGRAT_TEST_DB_URL"))
+		assert.NoError(t, err)
+		defer pool.Close()
+		pgxStore := &PgxStore{DB: pool}
+		assert.NoError(t, pgxStore.CreateLogTable())
+		_, err = th.db.Exec(`create table schema_migrations (version bigint not null primary key, dirty boolean not null);
+			insert into schema_migrations values (1, false)`)
+		assert.NoError(t, err)
+
+		count, err := ImportGolangMigrate(pgxStore, migrations, ImportOptions{Repo: "billing", Versions: []string{"1", "2"}})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assertImportedIndexes(t, migrations, "billing", []int{0})
+	})
+
+	t.Run("db error", func(t *testing.T) {
+		_, err := ImportGoose(errorStoreMock{wrapped: th.pgStore, errFetchImportedVersions: true}, migrations, ImportOptions{Repo: "auth"})
+		assert.EqualError(t, err, exampleErr.Error())
+	})
+}
+
+func assertImportedIndexes(t *testing.T, migrations Migrations, repo Repo, expected []int) {
+	logs, err := th.pgStore.fetchAllMigrationLogs()
+	assert.NoError(t, err)
+	var indexes []int
+	for _, log := range logs {
+		if log.Repo == repo {
+			indexes = append(indexes, log.Idx)
+		}
+	}
+	assert.Equal(t, expected, indexes)
+
+	result, err := CheckLogTableIntegrity(th.pgStore, migrations)
+	assert.NoError(t, err)
+	assert.False(t, result.IsCorrupted)
+}
+
+func TestImportedIndexes(t *testing.T) {
+	applied, err := importedIndexes(4, nil, []importedVersion{{version: "2", applied: true, baseline: true}, {version: "4", applied: true}})
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{true, true, false, true}, applied)
+
+	applied, err = importedIndexes(2, []string{"20230101", "20230102"}, []importedVersion{{version: "20230102", applied: true}, {version: "20230102", applied: false}})
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{false, false}, applied)
+
+	_, err = importedIndexes(2, []string{"1"}, nil)
+	assert.ErrorIs(t, err, errImportVersionsCount)
+
+	_, err = importedIndexes(2, nil, []importedVersion{{version: "3", applied: true}})
+	assert.ErrorIs(t, err, errUnknownImportedVersion)
+}
+
+func TestImportOptionsTable(t *testing.T) {
+	table, err := ImportOptions{}.table(golangMigrateTool)
+	assert.NoError(t, err)
+	assert.Equal(t, quotedIdentifier(`"schema_migrations"`), table)
+
+	table, err = ImportOptions{Table: "legacy.Schema_Migrations"}.table(golangMigrateTool)
+	assert.NoError(t, err)
+	assert.Equal(t, quotedIdentifier(`"legacy"."Schema_Migrations"`), table)
+
+	table, err = ImportOptions{Table: `x"; drop table users; --`}.table(gooseTool)
+	assert.NoError(t, err)
+	assert.Equal(t, quotedIdentifier(`"x""; drop table users; --"`), table)
+
+	for _, name := range []string{"a.b.c", ".schema_migrations", "legacy."} {
+		_, err = ImportOptions{Table: name}.table(flywayTool)
+		assert.ErrorIs(t, err, errInvalidImportTable)
+	}
+}
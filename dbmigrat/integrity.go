@@ -11,6 +11,21 @@ func CheckLogTableIntegrity(s store, migrations Migrations) (*IntegrityCheckResu
 
 	result := newIntegrityCheckResult()
 
+	appliedIndexes := appliedIndexesByRepo(migrationLogs)
+	for repo, indexes := range appliedIndexes {
+		lastIdx := -1
+		for idx := range indexes {
+			if idx > lastIdx {
+				lastIdx = idx
+			}
+		}
+		for idx := 0; idx < lastIdx && idx < len(migrations[repo]); idx++ {
+			if !indexes[idx] && !migrations[repo][idx].conditional() {
+				result.Gaps[repo] = append(result.Gaps[repo], PendingMigration{Idx: idx, Description: migrations[repo][idx].Description})
+			}
+		}
+	}
+
 	for _, log := range migrationLogs {
 		repoMigrations, ok := migrations[log.Repo]
 		if !ok {
@@ -26,7 +41,10 @@ func CheckLogTableIntegrity(s store, migrations Migrations) (*IntegrityCheckResu
 
 		if log.Checksum != sha1Checksum(repoMigrations[log.Idx].Up) {
 			result.IsCorrupted = true
-			result.InvalidChecksums[log.Repo] = append(result.RedundantMigrations[log.Repo], log)
+			result.InvalidChecksums[log.Repo] = append(result.InvalidChecksums[log.Repo], log)
+			if log.Description != repoMigrations[log.Idx].Description {
+				result.ConflictingMigrations[log.Repo] = append(result.ConflictingMigrations[log.Repo], log)
+			}
 		}
 	}
 
@@ -35,18 +53,31 @@ func CheckLogTableIntegrity(s store, migrations Migrations) (*IntegrityCheckResu
 
 func newIntegrityCheckResult() *IntegrityCheckResult {
 	return &IntegrityCheckResult{
-		IsCorrupted:         false,
-		RedundantRepos:      map[Repo]bool{},
-		RedundantMigrations: map[Repo][]migrationLog{},
-		InvalidChecksums:    map[Repo][]migrationLog{},
+		IsCorrupted:           false,
+		RedundantRepos:        map[Repo]bool{},
+		RedundantMigrations:   map[Repo][]MigrationLog{},
+		InvalidChecksums:      map[Repo][]MigrationLog{},
+		ConflictingMigrations: map[Repo][]MigrationLog{},
+		Gaps:                  map[Repo][]PendingMigration{},
 	}
 }
 
 // IntegrityCheckResult contains information about objects which exist in DB log
 // but not in passed migrations to the CheckLogTableIntegrity func.
+//
+// ConflictingMigrations is subset of InvalidChecksums containing logs which description differs
+// from description of passed migration with the same index. It usually means that
+// migrations with the same index were added in concurrent branches
+// (see RenumberMigrationFiles for resolving such conflicts).
+//
+// Gaps contains migrations missing from log which index is lower than index of the last applied migration.
+// Gaps do not make log corrupted, they can be applied with MigrateOptions.OutOfOrder.
+// Conditional migrations (e.g. restricted to other environment) are not reported as gaps.
 type IntegrityCheckResult struct {
-	IsCorrupted         bool
-	RedundantRepos      map[Repo]bool
-	RedundantMigrations map[Repo][]migrationLog
-	InvalidChecksums    map[Repo][]migrationLog
+	IsCorrupted           bool                        `json:"isCorrupted"`
+	RedundantRepos        map[Repo]bool               `json:"redundantRepos"`
+	RedundantMigrations   map[Repo][]MigrationLog     `json:"redundantMigrations"`
+	InvalidChecksums      map[Repo][]MigrationLog     `json:"invalidChecksums"`
+	ConflictingMigrations map[Repo][]MigrationLog     `json:"conflictingMigrations"`
+	Gaps                  map[Repo][]PendingMigration `json:"gaps"`
 }
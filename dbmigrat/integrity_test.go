@@ -38,7 +38,7 @@ func TestCheckLogTableIntegrity(t *testing.T) {
 	t.Run("Not corrupted log with one migration and extra migrations passed from outside", func(t *testing.T) {
 		assert.NoError(t, truncateLogTable())
 		upSql := "create table foo (id integer primary key)"
-		assert.NoError(t, th.pgStore.insertLogs([]migrationLog{{
+		assert.NoError(t, th.pgStore.insertLogs([]MigrationLog{{
 			Idx:             0,
 			Repo:            "repo1",
 			MigrationSerial: 0,
@@ -60,46 +60,79 @@ func TestCheckLogTableIntegrity(t *testing.T) {
 
 	t.Run("Corrupted log", func(t *testing.T) {
 		assert.NoError(t, truncateLogTable())
-		invalidChecksum := migrationLog{
+		invalidChecksum := MigrationLog{
 			Idx:             0,
 			Repo:            "repo1",
 			MigrationSerial: 0,
 			Checksum:        "",
 			Description:     "example migration invalid checksum",
 		}
-		redundantMigration := migrationLog{
+		redundantMigration := MigrationLog{
 			Idx:             1,
 			Repo:            "repo1",
 			MigrationSerial: 0,
 			Checksum:        sha1Checksum("example"),
 			Description:     "example redundant migration",
 		}
-		redundantRepo := migrationLog{
+		redundantRepo := MigrationLog{
 			Idx:             0,
 			Repo:            "repoRedundant",
 			MigrationSerial: 0,
 			Checksum:        sha1Checksum("example"),
 			Description:     "example migration redundant repo",
 		}
-		assert.NoError(t, th.pgStore.insertLogs([]migrationLog{invalidChecksum, redundantMigration, redundantRepo}))
+		conflictingMigration := MigrationLog{
+			Idx:             0,
+			Repo:            "repo2",
+			MigrationSerial: 0,
+			Checksum:        sha1Checksum("migration from other branch"),
+			Description:     "migration from other branch",
+		}
+		assert.NoError(t, th.pgStore.insertLogs([]MigrationLog{invalidChecksum, redundantMigration, redundantRepo, conflictingMigration}))
 
 		result, err := CheckLogTableIntegrity(th.pgStore, Migrations{
 			"repo1": {
-				{Up: "sql other than stored in log"},
+				{Up: "sql other than stored in log", Description: "example migration invalid checksum"},
+			},
+			"repo2": {
+				{Up: "migration from this branch", Description: "migration from this branch"},
 			},
 		})
 		assert.NoError(t, err)
 		// Set AppliedAt to be the same as inserted one
 		redundantMigration.AppliedAt = result.RedundantMigrations["repo1"][0].AppliedAt
 		invalidChecksum.AppliedAt = result.InvalidChecksums["repo1"][0].AppliedAt
+		conflictingMigration.AppliedAt = result.ConflictingMigrations["repo2"][0].AppliedAt
 		assert.Equal(t, &IntegrityCheckResult{
-			IsCorrupted:         true,
-			RedundantRepos:      map[Repo]bool{"repoRedundant": true},
-			RedundantMigrations: map[Repo][]migrationLog{"repo1": {redundantMigration}},
-			InvalidChecksums:    map[Repo][]migrationLog{"repo1": {invalidChecksum}},
+			IsCorrupted:           true,
+			RedundantRepos:        map[Repo]bool{"repoRedundant": true},
+			RedundantMigrations:   map[Repo][]MigrationLog{"repo1": {redundantMigration}},
+			InvalidChecksums:      map[Repo][]MigrationLog{"repo1": {invalidChecksum}, "repo2": {conflictingMigration}},
+			ConflictingMigrations: map[Repo][]MigrationLog{"repo2": {conflictingMigration}},
+			Gaps:                  map[Repo][]PendingMigration{},
 		}, result)
 	})
 
+	t.Run("Log with gaps is not corrupted", func(t *testing.T) {
+		assert.NoError(t, truncateLogTable())
+		migrations := Migrations{"repo1": {
+			{Up: "first", Description: "first"},
+			{Up: "hotfix", Description: "hotfix"},
+			{Up: "third", Description: "third"},
+			{Up: "pending", Description: "pending"},
+		}}
+		assert.NoError(t, th.pgStore.insertLogs([]MigrationLog{
+			{Idx: 0, Repo: "repo1", Checksum: sha1Checksum("first"), Description: "first"},
+			{Idx: 2, Repo: "repo1", Checksum: sha1Checksum("third"), Description: "third"},
+		}))
+
+		result, err := CheckLogTableIntegrity(th.pgStore, migrations)
+		assert.NoError(t, err)
+		expected := newIntegrityCheckResult()
+		expected.Gaps["repo1"] = []PendingMigration{{Idx: 1, Description: "hotfix"}}
+		assert.Equal(t, expected, result)
+	})
+
 	t.Run("db error", func(t *testing.T) {
 		storeMock := errorStoreMock{wrapped: th.pgStore, errFetchAllMigrationLogs: true}
 		res, err := CheckLogTableIntegrity(storeMock, Migrations{})
@@ -0,0 +1,168 @@
+// Package memfs implements read-only file system kept in memory,
+// returned by dbmigrat.TarFS and gitsource.TreeFS.
+package memfs
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FS maps slash separated paths (valid according to fs.ValidPath) to files.
+// Directories are implied by paths of files they contain.
+type FS map[string]*File
+
+// File is content of regular file.
+type File struct {
+	Data    []byte
+	Mode    fs.FileMode
+	ModTime time.Time
+}
+
+// Open implements fs.FS.
+func (fileSys FS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if file, ok := fileSys[name]; ok {
+		return &openFile{info: fileInfo{name: path.Base(name), file: file}, reader: bytes.NewReader(file.Data)}, nil
+	}
+
+	entries := fileSys.dirEntries(name)
+	if entries == nil && name != "." {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &openDir{info: fileInfo{name: path.Base(name), dir: true}, path: name, entries: entries}, nil
+}
+
+// dirEntries returns sorted entries of directory under name or nil when there is no such directory.
+func (fileSys FS) dirEntries(name string) []fs.DirEntry {
+	prefix := name + "/"
+	if name == "." {
+		prefix = ""
+	}
+	children := map[string]fs.FileInfo{}
+	for filePath, file := range fileSys {
+		if !strings.HasPrefix(filePath, prefix) {
+			continue
+		}
+		child, rest, isDir := strings.Cut(filePath[len(prefix):], "/")
+		if isDir {
+			children[child] = fileInfo{name: child, dir: true}
+		} else if rest == "" {
+			children[child] = fileInfo{name: child, file: file}
+		}
+	}
+	if len(children) == 0 {
+		return nil
+	}
+
+	entries := make([]fs.DirEntry, 0, len(children))
+	for _, info := range children {
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries
+}
+
+type fileInfo struct {
+	name string
+	file *File
+	dir  bool
+}
+
+func (i fileInfo) Name() string {
+	return i.name
+}
+
+func (i fileInfo) Size() int64 {
+	if i.dir {
+		return 0
+	}
+	return int64(len(i.file.Data))
+}
+
+func (i fileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0555
+	}
+	return i.file.Mode
+}
+
+func (i fileInfo) ModTime() time.Time {
+	if i.dir {
+		return time.Time{}
+	}
+	return i.file.ModTime
+}
+
+func (i fileInfo) IsDir() bool {
+	return i.dir
+}
+
+func (i fileInfo) Sys() any {
+	return nil
+}
+
+type openFile struct {
+	info   fileInfo
+	reader *bytes.Reader
+}
+
+func (f *openFile) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+func (f *openFile) Read(b []byte) (int, error) {
+	return f.reader.Read(b)
+}
+
+func (f *openFile) Seek(offset int64, whence int) (int64, error) {
+	return f.reader.Seek(offset, whence)
+}
+
+func (f *openFile) ReadAt(b []byte, offset int64) (int, error) {
+	return f.reader.ReadAt(b, offset)
+}
+
+func (f *openFile) Close() error {
+	return nil
+}
+
+type openDir struct {
+	info    fileInfo
+	path    string
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *openDir) Stat() (fs.FileInfo, error) {
+	return d.info, nil
+}
+
+func (d *openDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.path, Err: fs.ErrInvalid}
+}
+
+// ReadDir implements fs.ReadDirFile.
+func (d *openDir) ReadDir(count int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if count > 0 && len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	if count > 0 && count < len(remaining) {
+		remaining = remaining[:count]
+	}
+	d.offset += len(remaining)
+	return remaining, nil
+}
+
+func (d *openDir) Close() error {
+	return nil
+}
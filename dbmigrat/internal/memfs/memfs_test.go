@@ -0,0 +1,33 @@
+package memfs
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFS(t *testing.T) {
+	modTime := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+	fileSys := FS{
+		"auth/migrations/0.init.up.sql":   {Data: []byte("create table users ()"), Mode: 0644, ModTime: modTime},
+		"auth/migrations/0.init.down.sql": {Data: []byte("drop table users"), Mode: 0644, ModTime: modTime},
+		"README.md":                       {Data: []byte("# migrations")},
+	}
+	assert.NoError(t, fstest.TestFS(fileSys, "auth/migrations/0.init.up.sql", "auth/migrations/0.init.down.sql", "README.md"))
+
+	entries, err := fs.ReadDir(fileSys, ".")
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "README.md", entries[0].Name())
+	assert.True(t, entries[1].IsDir())
+
+	_, err = fileSys.Open("billing")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	_, err = fileSys.Open("/auth")
+	assert.ErrorIs(t, err, fs.ErrInvalid)
+	_, err = fs.ReadDir(FS{}, ".")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,247 @@
+package dbmigrat
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Lint checks migrations' SQL for patterns risky for running Postgres database, e.g. long locks
+// or changes breaking code which is still deployed. It does not connect to database,
+// so it can be run in CI before Migrate.
+//
+// SQL is analyzed statement by statement with regular expressions, it is not fully parsed.
+// Comments, string literals and dollar-quoted bodies (e.g. of functions) are ignored.
+func Lint(migrations Migrations, opts LintOptions) []LintIssue {
+	disabled := map[LintRule]bool{}
+	for _, rule := range opts.DisabledRules {
+		disabled[rule] = true
+	}
+
+	var issues []LintIssue
+	for _, repo := range sortedRepos(migrations) {
+		for idx, migration := range migrations[repo] {
+			found := lintStatements(splitStatements(migration.Up), up, opts)
+			if !migration.Irreversible {
+				found = append(found, lintStatements(splitStatements(migration.Down), down, opts)...)
+			}
+			for _, issue := range found {
+				if disabled[issue.Rule] {
+					continue
+				}
+				issue.Repo = repo
+				issue.Idx = idx
+				issue.Description = migration.Description
+				issues = append(issues, issue)
+			}
+		}
+	}
+
+	return issues
+}
+
+func lintStatements(statements []string, d direction, opts LintOptions) []LintIssue {
+	createdTables := map[string]bool{}
+	for _, statement := range statements {
+		if match := createTableRegexp.FindStringSubmatch(statement); match != nil {
+			createdTables[unquoteIdentifier(match[1])] = true
+		}
+	}
+
+	var issues []LintIssue
+	addIssue := func(rule LintRule, statement string, message string) {
+		issues = append(issues, LintIssue{Direction: string(d), Rule: rule, Statement: statement, Message: message})
+	}
+	for _, statement := range statements {
+		clauses := []string{statement}
+		if match := alterTableRegexp.FindStringSubmatch(statement); match != nil {
+			clauses = splitClauses(statement[len(match[0]):])
+		}
+
+		if d == up {
+			if match := createIndexRegexp.FindStringSubmatch(statement); match != nil && match[2] == "" {
+				table := unquoteIdentifier(match[3])
+				if opts.isBigTable(table, createdTables) {
+					addIssue(RuleIndexWithoutConcurrently, statement, fmt.Sprintf("index on table %s is created without concurrently, table is locked for writes", table))
+				}
+			}
+			for _, clause := range clauses {
+				if addColumnRegexp.MatchString(clause) && notNullRegexp.MatchString(clause) && !defaultRegexp.MatchString(clause) {
+					addIssue(RuleNotNullWithoutDefault, statement, "not null column is added without default, it fails for non-empty table")
+				}
+				if renameColumnRegexp.MatchString(clause) {
+					addIssue(RuleRenameColumn, statement, "renamed column breaks code using its old name")
+				}
+				if strings.HasPrefix(clause, "drop ") {
+					addIssue(RuleDropInUp, statement, "up drops object, data is lost and code using it breaks")
+				}
+			}
+		}
+
+		if d == down {
+			for _, clause := range clauses {
+				if strings.HasPrefix(clause, "drop ") && !ifExistsRegexp.MatchString(clause) {
+					addIssue(RuleDropWithoutIfExists, statement, "down drops object without if exists, it fails when up was applied partially")
+				}
+			}
+		}
+	}
+
+	return issues
+}
+
+func (opts LintOptions) isBigTable(table string, createdTables map[string]bool) bool {
+	if opts.BigTables == nil {
+		return !createdTables[table]
+	}
+	for _, bigTable := range opts.BigTables {
+		if bigTable == table {
+			return true
+		}
+	}
+	return false
+}
+
+// splitStatements splits SQL into statements. Returned statements are lowercase,
+// do not contain comments, contents of string literals, contents of dollar-quoted strings
+// and consecutive whitespaces.
+func splitStatements(sql string) []string {
+	var statements []string
+	var current strings.Builder
+	flush := func() {
+		statement := strings.ToLower(strings.Join(strings.Fields(current.String()), " "))
+		if statement != "" {
+			statements = append(statements, statement)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(sql); i++ {
+		switch {
+		case strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = len(sql) - i
+			}
+			i += end
+			current.WriteByte(' ')
+		case strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				end = len(sql) - i - 2
+			}
+			i += end + 3
+			current.WriteByte(' ')
+		case sql[i] == '\'':
+			end := i + 1
+			for end < len(sql) && (sql[end] != '\'' || strings.HasPrefix(sql[end:], "''")) {
+				if sql[end] == '\'' {
+					end++
+				}
+				end++
+			}
+			i = end
+			current.WriteString("''")
+		case sql[i] == '"':
+			end := strings.IndexByte(sql[i+1:], '"')
+			if end < 0 {
+				end = len(sql) - i - 2
+			}
+			current.WriteString(sql[i : i+end+2])
+			i += end + 1
+		case sql[i] == '$':
+			tag := dollarQuoteRegexp.FindString(sql[i:])
+			if tag == "" {
+				current.WriteByte(sql[i])
+				continue
+			}
+			end := strings.Index(sql[i+len(tag):], tag)
+			if end < 0 {
+				end = len(sql) - i - len(tag)
+			}
+			i += len(tag) + end + len(tag) - 1
+			current.WriteString("$$")
+		case sql[i] == ';':
+			flush()
+		default:
+			current.WriteByte(sql[i])
+		}
+	}
+	flush()
+
+	return statements
+}
+
+// splitClauses splits comma separated clauses of alter table statement (commas inside parentheses are ignored).
+func splitClauses(clauses string) []string {
+	var result []string
+	depth := 0
+	start := 0
+	for i, char := range clauses {
+		switch char {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				result = append(result, strings.TrimSpace(clauses[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(result, strings.TrimSpace(clauses[start:]))
+}
+
+func unquoteIdentifier(identifier string) string {
+	parts := strings.Split(identifier, ".")
+	return strings.Trim(parts[len(parts)-1], `"`)
+}
+
+// LintOptions allows for configuring Lint.
+type LintOptions struct {
+	// BigTables lists tables on which index must be created concurrently.
+	// When nil, every table not created in the same migration is considered to be big.
+	BigTables []string
+	// DisabledRules lists rules which are not checked.
+	DisabledRules []LintRule
+}
+
+// LintIssue describes risky statement found by Lint.
+// Direction is "up" or "down".
+type LintIssue struct {
+	Repo        Repo
+	Idx         int
+	Description string
+	Direction   string
+	Rule        LintRule
+	Statement   string
+	Message     string
+}
+
+func (i LintIssue) String() string {
+	return fmt.Sprintf("%s: migration %d (%s) %s: %s [%s]\n\t%s", i.Repo, i.Idx, i.Description, i.Direction, i.Message, i.Rule, i.Statement)
+}
+
+type LintRule string
+
+const (
+	RuleNotNullWithoutDefault    LintRule = "not-null-without-default"
+	RuleIndexWithoutConcurrently LintRule = "index-without-concurrently"
+	RuleRenameColumn             LintRule = "rename-column"
+	RuleDropInUp                 LintRule = "drop-in-up"
+	RuleDropWithoutIfExists      LintRule = "drop-without-if-exists"
+)
+
+var (
+	identifierPattern  = `((?:"[^"]+"|[\w$]+)(?:\.(?:"[^"]+"|[\w$]+))?)`
+	alterTableRegexp   = regexp.MustCompile(`^alter table (?:if exists )?(?:only )?` + identifierPattern + `\*? `)
+	createTableRegexp  = regexp.MustCompile(`^create (?:(?:global |local )?(?:temporary |temp )|unlogged )?table (?:if not exists )?` + identifierPattern)
+	createIndexRegexp  = regexp.MustCompile(`^create (unique )?index (concurrently )?(?:if not exists )?(?:[\w$"]+ )?on (?:only )?` + identifierPattern)
+	addColumnRegexp    = regexp.MustCompile(`^add (?:column )?(?:if not exists )?[\w$"]+ `)
+	notNullRegexp      = regexp.MustCompile(`\bnot null\b`)
+	defaultRegexp      = regexp.MustCompile(`\bdefault\b`)
+	renameColumnRegexp = regexp.MustCompile(`^rename (?:column )?[\w$"]+ to `)
+	ifExistsRegexp     = regexp.MustCompile(`\bif exists\b`)
+	dollarQuoteRegexp  = regexp.MustCompile(`^\$(?:[a-zA-Z_][\w]*)?\$`)
+)
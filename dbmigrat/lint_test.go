@@ -0,0 +1,80 @@
+package dbmigrat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	t.Run("migrations without risky patterns", func(t *testing.T) {
+		assert.Empty(t, Lint(Migrations{
+			"auth": {
+				{
+					Up:   `create table users (id serial primary key, login varchar(32) not null); create index users_login_idx on users (login)`,
+					Down: `drop table if exists users`,
+				},
+				{
+					Up:   `alter table users add column status integer not null default 0, add column email varchar(255)`,
+					Down: `alter table users drop column if exists status, drop column if exists email`,
+				},
+				{
+					Up:   `create index concurrently users_email_idx on users (email)`,
+					Down: `drop index concurrently if exists users_email_idx`,
+				},
+				{
+					Up:   `alter table users alter column email drop not null; comment on table users is 'drop table users'`,
+					Down: `alter table users alter column email set not null`,
+				},
+			},
+		}, LintOptions{}))
+	})
+
+	t.Run("risky patterns", func(t *testing.T) {
+		migrations := Migrations{
+			"billing": {{
+				Description: "risky",
+				Up: `-- drop table orders;
+					create index orders_user_id_idx on orders (user_id);
+					ALTER TABLE orders ADD COLUMN value_net decimal(12, 2) NOT NULL, RENAME COLUMN value TO value_gross;
+					drop table invoices`,
+				Down: `drop index orders_user_id_idx; alter table orders drop column value_net`,
+			}},
+			"auth": {{Up: `alter table users rename login to username`, Irreversible: true}},
+		}
+		assert.Equal(t, []LintIssue{
+			{Repo: "auth", Idx: 0, Direction: "up", Rule: RuleRenameColumn, Statement: "alter table users rename login to username", Message: "renamed column breaks code using its old name"},
+			{Repo: "billing", Idx: 0, Description: "risky", Direction: "up", Rule: RuleIndexWithoutConcurrently, Statement: "create index orders_user_id_idx on orders (user_id)", Message: "index on table orders is created without concurrently, table is locked for writes"},
+			{Repo: "billing", Idx: 0, Description: "risky", Direction: "up", Rule: RuleNotNullWithoutDefault, Statement: "alter table orders add column value_net decimal(12, 2) not null, rename column value to value_gross", Message: "not null column is added without default, it fails for non-empty table"},
+			{Repo: "billing", Idx: 0, Description: "risky", Direction: "up", Rule: RuleRenameColumn, Statement: "alter table orders add column value_net decimal(12, 2) not null, rename column value to value_gross", Message: "renamed column breaks code using its old name"},
+			{Repo: "billing", Idx: 0, Description: "risky", Direction: "up", Rule: RuleDropInUp, Statement: "drop table invoices", Message: "up drops object, data is lost and code using it breaks"},
+			{Repo: "billing", Idx: 0, Description: "risky", Direction: "down", Rule: RuleDropWithoutIfExists, Statement: "drop index orders_user_id_idx", Message: "down drops object without if exists, it fails when up was applied partially"},
+			{Repo: "billing", Idx: 0, Description: "risky", Direction: "down", Rule: RuleDropWithoutIfExists, Statement: "alter table orders drop column value_net", Message: "down drops object without if exists, it fails when up was applied partially"},
+		}, Lint(migrations, LintOptions{}))
+
+		issues := Lint(migrations, LintOptions{BigTables: []string{"invoices"}, DisabledRules: []LintRule{RuleDropWithoutIfExists, RuleRenameColumn}})
+		var rules []LintRule
+		for _, issue := range issues {
+			rules = append(rules, issue.Rule)
+		}
+		assert.Equal(t, []LintRule{RuleNotNullWithoutDefault, RuleDropInUp}, rules)
+	})
+}
+
+func TestSplitStatements(t *testing.T) {
+	assert.Equal(t, []string{
+		"create function f() returns text as $$ language sql",
+		"select '', \"quoted;\" from t",
+		"select 1",
+	}, splitStatements(`
+		CREATE FUNCTION f() RETURNS text AS $body$ select 'a;b'; $body$ LANGUAGE sql;
+		select 'it''s; fine', "Quoted;" /* comment; */ from t; -- comment;
+		select 1;
+	`))
+	assert.Empty(t, splitStatements("  ;\n-- only comment"))
+	assert.Equal(t, []string{"select $1, ''"}, splitStatements(`select $1, 'unclosed`))
+}
+
+func TestSplitClauses(t *testing.T) {
+	assert.Equal(t, []string{"add column a decimal(12, 2)", "drop column b"}, splitClauses("add column a decimal(12, 2), drop column b"))
+}
@@ -0,0 +1,265 @@
+package dbmigrat
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReadManifest is helper func which allows for reading migrations described by manifest file.
+// Manifest file can be JSON (.json extension) or YAML (any other extension). See Manifest for its structure.
+//
+// Example of valid YAML manifest:
+//
+//	repos:
+//	  - name: auth
+//	    migrations:
+//	      - description: create users table
+//	        up: auth/0.up.sql
+//	        down: auth/0.down.sql
+//	  - name: billing
+//	    dependsOn: [auth]
+//	    migrations:
+//	      - description: create orders table
+//	        up: billing/0.up.sql
+//	        irreversible: true
+//	      - description: index orders
+//	        up: billing/1.up.sql
+//	        down: billing/1.down.sql
+//	        noTransaction: true
+//	        tags: [v1.0.0]
+//	      - description: drop legacy column
+//	        up: billing/2.up.sql
+//	        irreversible: true
+//	        phase: contract
+//	      - description: seed orders
+//	        up: billing/3.up.sql
+//	        down: billing/3.down.sql
+//	        environments: [dev]
+//	        flags: [seed]
+func ReadManifest(fileSys fs.FS, name string) (Migrations, RepoOrder, error) {
+	manifest, err := decodeManifest(fileSys, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return manifest.Load(fileSys, path.Dir(name))
+}
+
+// ReadManifestRepeatable reads repeatable migrations described by manifest file (see ReadManifest).
+//
+// Example of valid YAML manifest with repeatable migrations:
+//
+//	repos:
+//	  - name: billing
+//	    migrations: []
+//	    repeatable:
+//	      - name: orders_summary_view
+//	        up: billing/repeatable/orders_summary_view.sql
+func ReadManifestRepeatable(fileSys fs.FS, name string) (RepeatableMigrations, error) {
+	manifest, err := decodeManifest(fileSys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest.LoadRepeatable(fileSys, path.Dir(name))
+}
+
+func decodeManifest(fileSys fs.FS, name string) (*Manifest, error) {
+	data, err := fs.ReadFile(fileSys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest Manifest
+	if path.Ext(name) == ".json" {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&manifest)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&manifest)
+	}
+	if err != nil {
+		return nil, errWithFileName{inner: err, fileName: name}
+	}
+
+	return &manifest, nil
+}
+
+// Load reads migrations files referenced by manifest.
+// Paths to files are relative to dir.
+//
+// Returned RepoOrder contains repos in order they are listed in manifest,
+// except that every repo is preceded by repos it depends on.
+func (m Manifest) Load(fileSys fs.FS, dir string) (Migrations, RepoOrder, error) {
+	repoOrder, err := m.repoOrder()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	migrations := Migrations{}
+	for _, repo := range m.Repos {
+		repoMigrations := make([]Migration, 0, len(repo.Migrations))
+		for _, manifestMigration := range repo.Migrations {
+			migration, err := manifestMigration.load(fileSys, dir)
+			if err != nil {
+				return nil, nil, err
+			}
+			repoMigrations = append(repoMigrations, *migration)
+		}
+		migrations[repo.Name] = repoMigrations
+	}
+
+	return migrations, repoOrder, nil
+}
+
+// LoadRepeatable reads repeatable migrations files referenced by manifest.
+// Paths to files are relative to dir.
+func (m Manifest) LoadRepeatable(fileSys fs.FS, dir string) (RepeatableMigrations, error) {
+	migrations := RepeatableMigrations{}
+	for _, repo := range m.Repos {
+		if len(repo.Repeatable) == 0 {
+			continue
+		}
+		repoMigrations := make([]RepeatableMigration, 0, len(repo.Repeatable))
+		for _, manifestMigration := range repo.Repeatable {
+			upData, err := fs.ReadFile(fileSys, path.Join(dir, manifestMigration.Up))
+			if err != nil {
+				return nil, err
+			}
+			repoMigrations = append(repoMigrations, RepeatableMigration{Name: manifestMigration.Name, Up: string(upData)})
+		}
+		migrations[repo.Name] = repoMigrations
+	}
+
+	return migrations, nil
+}
+
+func (m Manifest) repoOrder() (RepoOrder, error) {
+	reposByName := map[Repo]ManifestRepo{}
+	for _, repo := range m.Repos {
+		if _, ok := reposByName[repo.Name]; ok {
+			return nil, fmt.Errorf("%w (%s)", errManifestDuplicatedRepo, repo.Name)
+		}
+		reposByName[repo.Name] = repo
+	}
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := map[Repo]int{}
+	var repoOrder RepoOrder
+	var visit func(repo Repo) error
+	visit = func(repo Repo) error {
+		switch state[repo] {
+		case visiting:
+			return fmt.Errorf("%w (%s)", errManifestDependencyCycle, repo)
+		case visited:
+			return nil
+		}
+		state[repo] = visiting
+		for _, dependency := range reposByName[repo].DependsOn {
+			if _, ok := reposByName[dependency]; !ok {
+				return fmt.Errorf("%w (%s depends on %s)", errManifestUnknownDependency, repo, dependency)
+			}
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		state[repo] = visited
+		repoOrder = append(repoOrder, repo)
+		return nil
+	}
+
+	for _, repo := range m.Repos {
+		if err := visit(repo.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	return repoOrder, nil
+}
+
+func (m ManifestMigration) load(fileSys fs.FS, dir string) (*Migration, error) {
+	if m.Down == "" && !m.Irreversible {
+		return nil, fmt.Errorf("%w (%s)", errManifestMissingDown, m.Up)
+	}
+	if !m.Phase.valid() {
+		return nil, fmt.Errorf("%w (%s)", errUnknownPhase, m.Up)
+	}
+	upData, err := fs.ReadFile(fileSys, path.Join(dir, m.Up))
+	if err != nil {
+		return nil, err
+	}
+	migration := Migration{
+		Description:   m.Description,
+		Up:            string(upData),
+		Irreversible:  m.Irreversible,
+		NoTransaction: m.NoTransaction,
+		Phase:         m.Phase,
+		Environments:  m.Environments,
+		Flags:         m.Flags,
+		Extensions:    m.Extensions,
+		Tags:          m.Tags,
+	}
+	if m.Down != "" {
+		downData, err := fs.ReadFile(fileSys, path.Join(dir, m.Down))
+		if err != nil {
+			return nil, err
+		}
+		migration.Down = string(downData)
+	}
+
+	return &migration, nil
+}
+
+// Manifest describes repos and their migrations stored in SQL files.
+type Manifest struct {
+	Repos []ManifestRepo `json:"repos" yaml:"repos"`
+}
+
+// ManifestRepo describes single repo in Manifest.
+// DependsOn lists repos which migrations must be applied before migrations from this repo.
+type ManifestRepo struct {
+	Name       Repo                          `json:"name" yaml:"name"`
+	DependsOn  []Repo                        `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
+	Migrations []ManifestMigration           `json:"migrations" yaml:"migrations"`
+	Repeatable []ManifestRepeatableMigration `json:"repeatable,omitempty" yaml:"repeatable,omitempty"`
+}
+
+// ManifestMigration describes single Migration in ManifestRepo.
+// Up and Down are paths to SQL files. Down can be omitted only for irreversible migration.
+type ManifestMigration struct {
+	Description   string   `json:"description" yaml:"description"`
+	Up            string   `json:"up" yaml:"up"`
+	Down          string   `json:"down,omitempty" yaml:"down,omitempty"`
+	Irreversible  bool     `json:"irreversible,omitempty" yaml:"irreversible,omitempty"`
+	NoTransaction bool     `json:"noTransaction,omitempty" yaml:"noTransaction,omitempty"`
+	Phase         Phase    `json:"phase,omitempty" yaml:"phase,omitempty"`
+	Environments  []string `json:"environments,omitempty" yaml:"environments,omitempty"`
+	Flags         []string `json:"flags,omitempty" yaml:"flags,omitempty"`
+	Extensions    []string `json:"extensions,omitempty" yaml:"extensions,omitempty"`
+	Tags          []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+// ManifestRepeatableMigration describes single RepeatableMigration in ManifestRepo.
+// Up is path to SQL file.
+type ManifestRepeatableMigration struct {
+	Name string `json:"name" yaml:"name"`
+	Up   string `json:"up" yaml:"up"`
+}
+
+var (
+	errManifestDuplicatedRepo    = errors.New("manifest contains repo more than once")
+	errManifestDependencyCycle   = errors.New("manifest repos dependencies contain cycle")
+	errManifestUnknownDependency = errors.New("manifest repo depends on repo not present in manifest")
+	errManifestMissingDown       = errors.New("manifest migration must have down file or be irreversible")
+)
@@ -0,0 +1,119 @@
+package dbmigrat
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadManifest(t *testing.T) {
+	expectedMigrations := Migrations{
+		"auth": {
+			{Description: "create users table", Up: "create table users (id serial primary key);", Down: "drop table users;", Tags: []string{"v0.1.0", "v1.0.0"}},
+			{Description: "seed users", Up: "insert into users default values;", Down: "delete from users;", Environments: []string{"dev"}, Flags: []string{"seed"}},
+		},
+		"billing": {
+			{
+				Description:  "create orders table",
+				Up:           "create table orders (id serial primary key, user_id integer references users (id) not null);",
+				Irreversible: true,
+			},
+			{
+				Description:   "index orders user id",
+				Up:            "create index concurrently orders_user_id_idx on orders (user_id);",
+				Down:          "drop index concurrently orders_user_id_idx;",
+				NoTransaction: true,
+				Tags:          []string{"v1.0.0"},
+			},
+		},
+	}
+
+	for _, manifestPath := range []string{"testdata/manifest/dbmigrat.yaml", "testdata/manifest/dbmigrat.json"} {
+		t.Run(manifestPath, func(t *testing.T) {
+			migrations, repoOrder, err := ReadManifest(fixture, manifestPath)
+			assert.NoError(t, err)
+			assert.Equal(t, expectedMigrations, migrations)
+			assert.Equal(t, RepoOrder{"auth", "billing"}, repoOrder)
+		})
+	}
+
+	t.Run("returns error for unknown field", func(t *testing.T) {
+		fileSys := fstest.MapFS{"dbmigrat.yaml": {Data: []byte("repos:\n  - name: auth\n    depends: [billing]\n")}}
+		_, _, err := ReadManifest(fileSys, "dbmigrat.yaml")
+		assert.Error(t, err)
+	})
+
+	t.Run("returns error for missing file", func(t *testing.T) {
+		_, _, err := ReadManifest(fstest.MapFS{}, "dbmigrat.json")
+		assert.EqualError(t, err, "open dbmigrat.json: file does not exist")
+	})
+}
+
+func TestReadManifestRepeatable(t *testing.T) {
+	for _, manifestPath := range []string{"testdata/manifest/dbmigrat.yaml", "testdata/manifest/dbmigrat.json"} {
+		t.Run(manifestPath, func(t *testing.T) {
+			migrations, err := ReadManifestRepeatable(fixture, manifestPath)
+			assert.NoError(t, err)
+			assert.Equal(t, RepeatableMigrations{
+				"billing": {{Name: "orders_count", Up: "create or replace view orders_count as select count(*) from orders;"}},
+			}, migrations)
+		})
+	}
+
+	t.Run("returns error when referenced file is missing", func(t *testing.T) {
+		manifest := Manifest{Repos: []ManifestRepo{{Name: "auth", Repeatable: []ManifestRepeatableMigration{{Name: "view", Up: "view.sql"}}}}}
+		_, err := manifest.LoadRepeatable(fstest.MapFS{}, ".")
+		assert.EqualError(t, err, "open view.sql: file does not exist")
+	})
+}
+
+func TestManifestLoad(t *testing.T) {
+	t.Run("returns error when migration has no down file and is not irreversible", func(t *testing.T) {
+		manifest := Manifest{Repos: []ManifestRepo{{Name: "auth", Migrations: []ManifestMigration{{Up: "0.up.sql"}}}}}
+		_, _, err := manifest.Load(fstest.MapFS{"0.up.sql": {}}, ".")
+		assert.ErrorIs(t, err, errManifestMissingDown)
+	})
+	t.Run("returns error for unknown phase", func(t *testing.T) {
+		manifest := Manifest{Repos: []ManifestRepo{{Name: "auth", Migrations: []ManifestMigration{{Up: "0.up.sql", Irreversible: true, Phase: "cleanup"}}}}}
+		_, _, err := manifest.Load(fstest.MapFS{"0.up.sql": {}}, ".")
+		assert.ErrorIs(t, err, errUnknownPhase)
+	})
+	t.Run("returns error when referenced file is missing", func(t *testing.T) {
+		manifest := Manifest{Repos: []ManifestRepo{{Name: "auth", Migrations: []ManifestMigration{{Up: "0.up.sql", Down: "0.down.sql"}}}}}
+		_, _, err := manifest.Load(fstest.MapFS{"0.up.sql": {}}, ".")
+		assert.EqualError(t, err, "open 0.down.sql: file does not exist")
+	})
+}
+
+func TestManifestRepoOrder(t *testing.T) {
+	t.Run("dependencies precede dependent repos", func(t *testing.T) {
+		manifest := Manifest{Repos: []ManifestRepo{
+			{Name: "billing", DependsOn: []Repo{"auth", "inventory"}},
+			{Name: "delivery", DependsOn: []Repo{"billing"}},
+			{Name: "inventory"},
+			{Name: "auth"},
+		}}
+		repoOrder, err := manifest.repoOrder()
+		assert.NoError(t, err)
+		assert.Equal(t, RepoOrder{"auth", "inventory", "billing", "delivery"}, repoOrder)
+	})
+	t.Run("cycle", func(t *testing.T) {
+		manifest := Manifest{Repos: []ManifestRepo{
+			{Name: "auth", DependsOn: []Repo{"billing"}},
+			{Name: "billing", DependsOn: []Repo{"auth"}},
+		}}
+		_, err := manifest.repoOrder()
+		assert.ErrorIs(t, err, errManifestDependencyCycle)
+	})
+	t.Run("unknown dependency", func(t *testing.T) {
+		manifest := Manifest{Repos: []ManifestRepo{{Name: "auth", DependsOn: []Repo{"billing"}}}}
+		_, err := manifest.repoOrder()
+		assert.ErrorIs(t, err, errManifestUnknownDependency)
+	})
+	t.Run("duplicated repo", func(t *testing.T) {
+		manifest := Manifest{Repos: []ManifestRepo{{Name: "auth"}, {Name: "auth"}}}
+		_, err := manifest.repoOrder()
+		assert.ErrorIs(t, err, errManifestDuplicatedRepo)
+	})
+}
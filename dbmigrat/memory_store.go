@@ -0,0 +1,205 @@
+package dbmigrat
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"time"
+)
+
+// MemoryStore keeps migrations log, history and repeatable migrations log in memory.
+// Instead of executing migrations' SQL, it records it in Executed, so Migrate, Rollback,
+// CheckLogTableIntegrity and other functions can be exercised without a database (e.g. in unit tests or dry runs).
+//
+// Zero value is empty store, LoadMemoryStore returns store with migrations log loaded from JSON.
+// Like a database, MemoryStore discards changes made in rolled back transaction.
+type MemoryStore struct {
+	// Executed contains SQL executed in committed transactions (and outside transactions), in order of execution.
+	Executed []string
+	// Extensions are available for conditional migrations (see Migration.Extensions).
+	Extensions map[string]bool
+	// ExecFunc, when set, is called for every executed SQL. Returned error (e.g. simulating failing migration)
+	// causes SQL not to be recorded. Returned count is reported as count of affected rows (see MigrationLog.RowsAffected).
+	ExecFunc func(query string) (int64, error)
+
+	logs           []MigrationLog
+	history        []HistoryEntry
+	repeatableLogs []RepeatableLog
+	snapshot       *MemoryStore
+}
+
+// LoadMemoryStore returns MemoryStore with migrations log read from JSON array of MigrationLog
+// (e.g. snapshot of production dbmigrat_log table).
+func LoadMemoryStore(r io.Reader) (*MemoryStore, error) {
+	var logs []MigrationLog
+	err := json.NewDecoder(r).Decode(&logs)
+	if err != nil {
+		return nil, err
+	}
+	return &MemoryStore{logs: logs}, nil
+}
+
+// CreateLogTable does nothing, MemoryStore is ready to use without it.
+func (s *MemoryStore) CreateLogTable() error {
+	return nil
+}
+
+func (s *MemoryStore) fetchAllMigrationLogs() ([]MigrationLog, error) {
+	return append([]MigrationLog{}, s.logs...), nil
+}
+
+func (s *MemoryStore) fetchLastMigrationSerial() (int, error) {
+	lastSerial := -1
+	for _, log := range s.logs {
+		if log.MigrationSerial > lastSerial {
+			lastSerial = log.MigrationSerial
+		}
+	}
+	return lastSerial, nil
+}
+
+func (s *MemoryStore) insertLogs(logs []MigrationLog) error {
+	for _, log := range logs {
+		for _, existing := range s.logs {
+			if existing.Idx == log.Idx && existing.Repo == log.Repo {
+				return errMemoryLogExists
+			}
+		}
+		log.AppliedAt = time.Now()
+		s.logs = append(s.logs, log)
+	}
+	return nil
+}
+
+func (s *MemoryStore) fetchLastMigrationIndexes() (map[Repo]int, error) {
+	repoToMaxIdx := map[Repo]int{}
+	for _, log := range s.logs {
+		if maxIdx, ok := repoToMaxIdx[log.Repo]; !ok || log.Idx > maxIdx {
+			repoToMaxIdx[log.Repo] = log.Idx
+		}
+	}
+	return repoToMaxIdx, nil
+}
+
+func (s *MemoryStore) fetchReverseMigrationIndexesAfterSerial(serial int) (map[Repo][]int, error) {
+	var logs []MigrationLog
+	for _, log := range s.logs {
+		if log.MigrationSerial > serial {
+			logs = append(logs, log)
+		}
+	}
+	sort.Slice(logs, func(i, j int) bool {
+		if logs[i].MigrationSerial != logs[j].MigrationSerial {
+			return logs[i].MigrationSerial > logs[j].MigrationSerial
+		}
+		return logs[i].Idx > logs[j].Idx
+	})
+
+	repoToReverseMigrationIndexes := map[Repo][]int{}
+	for _, log := range logs {
+		repoToReverseMigrationIndexes[log.Repo] = append(repoToReverseMigrationIndexes[log.Repo], log.Idx)
+	}
+	return repoToReverseMigrationIndexes, nil
+}
+
+func (s *MemoryStore) deleteLogs(logs []MigrationLog) error {
+	for _, log := range logs {
+		remaining := s.logs[:0]
+		for _, existing := range s.logs {
+			if existing.Idx != log.Idx || existing.Repo != log.Repo {
+				remaining = append(remaining, existing)
+			}
+		}
+		s.logs = remaining
+	}
+	return nil
+}
+
+func (s *MemoryStore) insertHistory(entries []HistoryEntry) error {
+	for _, entry := range entries {
+		entry.ID = len(s.history) + 1
+		entry.RecordedAt = time.Now()
+		s.history = append(s.history, entry)
+	}
+	return nil
+}
+
+func (s *MemoryStore) fetchHistory() ([]HistoryEntry, error) {
+	return append([]HistoryEntry{}, s.history...), nil
+}
+
+func (s *MemoryStore) fetchAvailableExtensions() (map[string]bool, error) {
+	extensions := map[string]bool{}
+	for name, available := range s.Extensions {
+		extensions[name] = available
+	}
+	return extensions, nil
+}
+
+func (s *MemoryStore) fetchRepeatableLogs() ([]RepeatableLog, error) {
+	return append([]RepeatableLog{}, s.repeatableLogs...), nil
+}
+
+func (s *MemoryStore) upsertRepeatableLog(log RepeatableLog) error {
+	log.AppliedAt = time.Now()
+	for i, existing := range s.repeatableLogs {
+		if existing.Repo == log.Repo && existing.Name == log.Name {
+			s.repeatableLogs[i] = log
+			return nil
+		}
+	}
+	s.repeatableLogs = append(s.repeatableLogs, log)
+	return nil
+}
+
+// fetchImportedVersions returns error, as MemoryStore does not contain tables of other tools.
+func (s *MemoryStore) fetchImportedVersions(importedTool, quotedIdentifier) ([]importedVersion, error) {
+	return nil, errMemoryImport
+}
+
+// begin saves copy of current state, which is restored by rollback.
+func (s *MemoryStore) begin() error {
+	s.snapshot = &MemoryStore{
+		Executed:       append([]string{}, s.Executed...),
+		logs:           append([]MigrationLog{}, s.logs...),
+		history:        append([]HistoryEntry{}, s.history...),
+		repeatableLogs: append([]RepeatableLog{}, s.repeatableLogs...),
+	}
+	return nil
+}
+
+func (s *MemoryStore) rollback() error {
+	if s.snapshot == nil {
+		return nil
+	}
+	s.Executed = s.snapshot.Executed
+	s.logs = s.snapshot.logs
+	s.history = s.snapshot.history
+	s.repeatableLogs = s.snapshot.repeatableLogs
+	s.snapshot = nil
+	return nil
+}
+
+func (s *MemoryStore) commit() error {
+	s.snapshot = nil
+	return nil
+}
+
+func (s *MemoryStore) exec(query string) (int64, error) {
+	var rowsAffected int64
+	if s.ExecFunc != nil {
+		var err error
+		rowsAffected, err = s.ExecFunc(query)
+		if err != nil {
+			return 0, err
+		}
+	}
+	s.Executed = append(s.Executed, query)
+	return rowsAffected, nil
+}
+
+var (
+	errMemoryLogExists = errors.New("migrations log already contains migration with given idx and repo")
+	errMemoryImport    = errors.New("MemoryStore does not support importing from other tools")
+)
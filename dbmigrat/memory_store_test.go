@@ -0,0 +1,89 @@
+package dbmigrat
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	s := &MemoryStore{}
+	assert.NoError(t, s.CreateLogTable())
+	repoOrder := RepoOrder{"auth", "billing", "delivery"}
+
+	logCount, err := Migrate(s, th.migrations1, repoOrder)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, logCount)
+	logCount, err = Migrate(s, th.migrations2, repoOrder)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, logCount)
+	assert.Equal(t, []string{
+		th.migrations2["auth"][0].Up,
+		th.migrations2["auth"][1].Up,
+		th.migrations2["billing"][0].Up,
+		th.migrations2["billing"][1].Up,
+		th.migrations2["delivery"][0].Up,
+	}, s.Executed)
+
+	result, err := CheckLogTableIntegrity(s, th.migrations2)
+	assert.NoError(t, err)
+	assert.Equal(t, newIntegrityCheckResult(), result)
+
+	logCount, err = Rollback(s, th.migrations2, RepoOrder{"delivery", "billing", "auth"}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, logCount)
+	assert.Equal(t, []string{th.migrations2["delivery"][0].Down, th.migrations2["billing"][1].Down}, s.Executed[5:])
+
+	// # Failed migration discards changes of the whole transaction
+	s.ExecFunc = func(query string) (int64, error) {
+		if strings.HasPrefix(query, "create table delivery_status") {
+			return 0, exampleErr
+		}
+		return 0, nil
+	}
+	_, err = Migrate(s, th.migrations2, repoOrder)
+	assert.ErrorIs(t, err, exampleErr)
+	assert.Len(t, s.Executed, 7)
+	status, err := FetchStatus(s, th.migrations2, repoOrder)
+	assert.NoError(t, err)
+	assert.Len(t, status.Repos[1].Pending, 1)
+
+	history, err := FetchHistory(s)
+	assert.NoError(t, err)
+	assert.Equal(t, HistoryFailure, history[len(history)-1].Outcome)
+
+	// # Conditional migrations use Extensions
+	s = &MemoryStore{Extensions: map[string]bool{"postgis": true}}
+	migrations := Migrations{"geo": {
+		{Up: `create extension postgis`, Extensions: []string{"postgis"}},
+		{Up: `create extension timescaledb`, Extensions: []string{"timescaledb"}},
+	}}
+	logCount, err = Migrate(s, migrations, RepoOrder{"geo"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, logCount)
+	assert.Equal(t, []string{`create extension postgis`}, s.Executed)
+}
+
+func TestLoadMemoryStore(t *testing.T) {
+	s, err := LoadMemoryStore(strings.NewReader(`[
+		{"idx": 0, "repo": "auth", "migrationSerial": 0, "checksum": "` + sha1Checksum(th.migrations2["auth"][0].Up) + `", "appliedAt": "2022-06-01T12:00:00Z"},
+		{"idx": 1, "repo": "auth", "migrationSerial": 1, "checksum": "invalid", "appliedAt": "2022-06-02T12:00:00Z"}
+	]`))
+	assert.NoError(t, err)
+
+	result, err := CheckLogTableIntegrity(s, th.migrations2)
+	assert.NoError(t, err)
+	assert.True(t, result.IsCorrupted)
+	assert.Equal(t, 1, result.InvalidChecksums["auth"][0].Idx)
+
+	logCount, err := Migrate(s, th.migrations2, RepoOrder{"auth", "billing"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, logCount)
+	logs, err := s.fetchAllMigrationLogs()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, logs[3].MigrationSerial)
+
+	_, err = LoadMemoryStore(strings.NewReader(`{`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,99 @@
+package dbmigrat
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// metaMigrations create and upgrade dbmigrat's own tables. Version of applied meta migrations
+// is saved in dbmigrat_meta table. Meta migrations must never be changed once released,
+// changes of dbmigrat's tables are made by appending new meta migration.
+//
+// Meta migrations use "if not exists", so they can be applied to tables created
+// before dbmigrat_meta table was introduced.
+var metaMigrations = []string{
+	`create table if not exists dbmigrat_log
+	(
+	    idx              integer      not null,
+	    repo             varchar(255) not null,
+	    migration_serial integer      not null,
+	    checksum         bytea        not null,
+	    applied_at       timestamp    not null default current_timestamp,
+	    description      text         not null,
+	    primary key (idx, repo)
+	)`,
+	`create table if not exists dbmigrat_history
+	(
+	    id               serial       primary key,
+	    idx              integer      not null,
+	    repo             varchar(255) not null,
+	    migration_serial integer      not null,
+	    checksum         bytea        not null,
+	    description      text         not null,
+	    action           varchar(16)  not null,
+	    outcome          varchar(16)  not null,
+	    error            text         not null,
+	    actor            varchar(255) not null,
+	    host             varchar(255) not null,
+	    recorded_at      timestamp    not null default current_timestamp
+	)`,
+	`create table if not exists dbmigrat_repeatable_log
+	(
+	    repo       varchar(255) not null,
+	    name       varchar(255) not null,
+	    checksum   bytea        not null,
+	    applied_at timestamp    not null default current_timestamp,
+	    primary key (repo, name)
+	)`,
+	`alter table dbmigrat_log
+	    add column if not exists duration_ms   bigint not null default 0,
+	    add column if not exists rows_affected bigint`,
+}
+
+// migrateMeta applies meta migrations which were not applied yet.
+// dbmigrat_meta is locked until the end of transaction, so concurrent calls do not apply the same meta migration twice.
+func migrateMeta(tx metaAccessor) error {
+	_, err := tx.Exec(`create table if not exists dbmigrat_meta (version integer not null)`)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`lock table dbmigrat_meta in exclusive mode`)
+	if err != nil {
+		return err
+	}
+
+	var version sql.NullInt32
+	err = tx.Get(&version, `select max(version) from dbmigrat_meta`)
+	if err != nil {
+		return err
+	}
+	currentVersion := -1
+	if version.Valid {
+		currentVersion = int(version.Int32)
+	}
+	latestVersion := len(metaMigrations) - 1
+	if currentVersion > latestVersion {
+		return fmt.Errorf("%w (%d > %d)", errMetaVersionTooNew, currentVersion, latestVersion)
+	}
+	if currentVersion == latestVersion {
+		return nil
+	}
+
+	for _, metaMigration := range metaMigrations[currentVersion+1:] {
+		_, err = tx.Exec(metaMigration)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = tx.Exec(`delete from dbmigrat_meta`)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`insert into dbmigrat_meta (version) values ($1)`, latestVersion)
+	return err
+}
+
+type metaAccessor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Get(dest interface{}, query string, args ...interface{}) error
+}
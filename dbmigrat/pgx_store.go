@@ -0,0 +1,276 @@
+package dbmigrat
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PgxStore works like PostgresStore, but uses pgx driver instead of sqlx and lib/pq.
+// DB can be *pgxpool.Pool or *pgx.Conn.
+type PgxStore struct {
+	DB PgxConn
+	tx pgx.Tx
+}
+
+// PgxConn is implemented by *pgxpool.Pool and *pgx.Conn.
+type PgxConn interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// CreateLogTable works like PostgresStore.CreateLogTable.
+func (s PgxStore) CreateLogTable() error {
+	tx, err := s.DB.Begin(context.Background())
+	if err != nil {
+		return err
+	}
+	err = migrateMeta(pgxMetaAccessor{tx: tx})
+	if err != nil {
+		return multierror.Append(err, tx.Rollback(context.Background()))
+	}
+	return tx.Commit(context.Background())
+}
+
+func (s PgxStore) fetchAllMigrationLogs() ([]MigrationLog, error) {
+	rows, err := s.getDbAccessor().Query(context.Background(), `
+		select idx, repo, migration_serial, convert_from(checksum, 'UTF8') as checksum, applied_at, description, duration_ms, rows_affected
+		from dbmigrat_log
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[MigrationLog])
+}
+
+func (s PgxStore) fetchLastMigrationSerial() (int, error) {
+	var result sql.NullInt32
+	err := s.getDbAccessor().QueryRow(context.Background(), `select max(migration_serial) from dbmigrat_log`).Scan(&result)
+	if err != nil {
+		return -1, err
+	}
+	if !result.Valid {
+		return -1, nil
+	}
+	return int(result.Int32), nil
+}
+
+func (s PgxStore) insertLogs(logs []MigrationLog) error {
+	for _, log := range logs {
+		_, err := s.getDbAccessor().Exec(context.Background(), `
+			insert into dbmigrat_log (idx, repo, migration_serial, checksum, applied_at, description, duration_ms, rows_affected)
+			values ($1, $2, $3, convert_to($4, 'UTF8'), default, $5, $6, $7)
+			`,
+			log.Idx, log.Repo, log.MigrationSerial, log.Checksum, log.Description, log.DurationMs, log.RowsAffected,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s PgxStore) fetchLastMigrationIndexes() (map[Repo]int, error) {
+	rows, err := s.getDbAccessor().Query(context.Background(), `select max(idx) as idx, repo from dbmigrat_log group by repo`)
+	if err != nil {
+		return nil, err
+	}
+	dest, err := pgx.CollectRows(rows, pgx.RowToStructByName[repoIdx])
+	if err != nil {
+		return nil, err
+	}
+
+	repoToMaxIdx := map[Repo]int{}
+	for _, res := range dest {
+		repoToMaxIdx[res.Repo] = res.Idx
+	}
+
+	return repoToMaxIdx, nil
+}
+
+func (s PgxStore) fetchReverseMigrationIndexesAfterSerial(serial int) (map[Repo][]int, error) {
+	rows, err := s.getDbAccessor().Query(context.Background(), `select idx, repo from dbmigrat_log where migration_serial > $1 order by migration_serial desc, idx desc`, serial)
+	if err != nil {
+		return nil, err
+	}
+	dest, err := pgx.CollectRows(rows, pgx.RowToStructByName[repoIdx])
+	if err != nil {
+		return nil, err
+	}
+
+	repoToReverseMigrationIndexes := map[Repo][]int{}
+	for _, res := range dest {
+		repoToReverseMigrationIndexes[res.Repo] = append(repoToReverseMigrationIndexes[res.Repo], res.Idx)
+	}
+
+	return repoToReverseMigrationIndexes, nil
+}
+
+func (s PgxStore) deleteLogs(logs []MigrationLog) error {
+	for _, log := range logs {
+		_, err := s.getDbAccessor().Exec(context.Background(), `delete from dbmigrat_log where idx = $1 and repo = $2`, log.Idx, log.Repo)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s PgxStore) insertHistory(entries []HistoryEntry) error {
+	for _, entry := range entries {
+		_, err := s.getDbAccessor().Exec(context.Background(), `
+			insert into dbmigrat_history (idx, repo, migration_serial, checksum, description, action, outcome, error, actor, host, recorded_at)
+			values ($1, $2, $3, convert_to($4, 'UTF8'), $5, $6, $7, $8, $9, $10, default)
+			`,
+			entry.Idx, entry.Repo, entry.MigrationSerial, entry.Checksum, entry.Description,
+			entry.Action, entry.Outcome, entry.Error, entry.Actor, entry.Host,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s PgxStore) fetchHistory() ([]HistoryEntry, error) {
+	rows, err := s.getDbAccessor().Query(context.Background(), `
+		select id, idx, repo, migration_serial, convert_from(checksum, 'UTF8') as checksum, description,
+		       action, outcome, error, actor, host, recorded_at
+		from dbmigrat_history
+		order by id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[HistoryEntry])
+}
+
+func (s PgxStore) fetchRepeatableLogs() ([]RepeatableLog, error) {
+	rows, err := s.getDbAccessor().Query(context.Background(), `
+		select repo, name, convert_from(checksum, 'UTF8') as checksum, applied_at
+		from dbmigrat_repeatable_log
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[RepeatableLog])
+}
+
+func (s PgxStore) upsertRepeatableLog(log RepeatableLog) error {
+	_, err := s.getDbAccessor().Exec(context.Background(), `
+			insert into dbmigrat_repeatable_log (repo, name, checksum, applied_at)
+			values ($1, $2, convert_to($3, 'UTF8'), default)
+			on conflict (repo, name) do update set checksum = excluded.checksum, applied_at = excluded.applied_at
+			`,
+		log.Repo, log.Name, log.Checksum,
+	)
+
+	return err
+}
+
+func (s PgxStore) fetchAvailableExtensions() (map[string]bool, error) {
+	rows, err := s.getDbAccessor().Query(context.Background(), `select name from pg_available_extensions`)
+	if err != nil {
+		return nil, err
+	}
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+	extensions := map[string]bool{}
+	for _, name := range names {
+		extensions[name] = true
+	}
+	return extensions, nil
+}
+
+func (s PgxStore) fetchImportedVersions(tool importedTool, table quotedIdentifier) ([]importedVersion, error) {
+	rows, err := s.getDbAccessor().Query(context.Background(), tool.queryFrom(table))
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (importedVersion, error) {
+		var version importedVersion
+		err := row.Scan(&version.version, &version.applied, &version.baseline, &version.dirty)
+		return version, err
+	})
+}
+
+func (s PgxStore) fetchSchemaSnapshot() ([]string, error) {
+	rows, err := s.getDbAccessor().Query(context.Background(), schemaSnapshotQuery)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+func (s *PgxStore) begin() error {
+	tx, err := s.DB.Begin(context.Background())
+	s.tx = tx
+	return err
+}
+
+func (s *PgxStore) rollback() error {
+	if s.tx == nil {
+		return nil
+	}
+	err := s.tx.Rollback(context.Background())
+	s.tx = nil
+	return err
+}
+
+func (s *PgxStore) commit() error {
+	err := s.tx.Commit(context.Background())
+	s.tx = nil
+	return err
+}
+
+// exec executes query and returns count of rows affected by its last statement.
+func (s PgxStore) exec(query string) (int64, error) {
+	tag, err := s.getDbAccessor().Exec(context.Background(), query)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (s PgxStore) getDbAccessor() pgxAccessor {
+	if s.tx != nil {
+		return s.tx
+	}
+	return s.DB
+}
+
+type pgxAccessor interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// pgxMetaAccessor allows for applying meta migrations (see migrateMeta) in pgx transaction.
+type pgxMetaAccessor struct {
+	tx pgx.Tx
+}
+
+func (a pgxMetaAccessor) Exec(query string, args ...interface{}) (sql.Result, error) {
+	tag, err := a.tx.Exec(context.Background(), query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(tag.RowsAffected()), nil
+}
+
+func (a pgxMetaAccessor) Get(dest interface{}, query string, args ...interface{}) error {
+	return a.tx.QueryRow(context.Background(), query, args...).Scan(dest)
+}
+
+type repoIdx struct {
+	Idx  int
+	Repo Repo
+}
@@ -0,0 +1,72 @@
+package dbmigrat
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPgxStore(t *testing.T) {
+	assert.NoError(t, th.resetDB())
+	pool, err := pgxpool.New(context.Background(), os.Getenv("DBMIGRAT_TEST_DB_URL"))
+	assert.NoError(t, err)
+	defer pool.Close()
+	pgxStore := &PgxStore{DB: pool}
+	repoOrder := RepoOrder{"auth", "billing", "delivery"}
+
+	// # Create tables when they not exist and when they exist
+	assert.NoError(t, pgxStore.CreateLogTable())
+	assert.NoError(t, pgxStore.CreateLogTable())
+
+	migrations := Migrations{
+		"auth": append(th.migrations2["auth"],
+			Migration{Up: `create index concurrently users_username_idx on users (username)`, Down: `drop index concurrently users_username_idx`, NoTransaction: true},
+			Migration{Up: `insert into users default values`, Down: `delete from users`, Description: "seed users"},
+		),
+		"billing":  th.migrations2["billing"],
+		"delivery": th.migrations2["delivery"],
+	}
+	repeatable := RepeatableMigrations{"auth": {{Name: "users_count", Up: `create or replace view users_count as select count(*) from users`}}}
+	logCount, err := MigrateWithOptions(pgxStore, migrations, repoOrder, MigrateOptions{Repeatable: repeatable})
+	assert.NoError(t, err)
+	assert.Equal(t, 8, logCount)
+	logCount, err = MigrateWithOptions(pgxStore, migrations, repoOrder, MigrateOptions{Repeatable: repeatable})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, logCount)
+
+	// # Log saved by PgxStore is compatible with PostgresStore
+	result, err := CheckLogTableIntegrity(th.pgStore, migrations)
+	assert.NoError(t, err)
+	assert.Equal(t, newIntegrityCheckResult(), result)
+	result, err = CheckLogTableIntegrity(pgxStore, migrations)
+	assert.NoError(t, err)
+	assert.Equal(t, newIntegrityCheckResult(), result)
+	assert.NoError(t, CheckReady(pgxStore, migrations, repoOrder))
+
+	status, err := FetchStatus(pgxStore, migrations, repoOrder)
+	assert.NoError(t, err)
+	seed := status.Repos[0].Applied[3]
+	assert.Equal(t, "seed users", seed.Description)
+	assert.Equal(t, int64(1), *seed.RowsAffected)
+
+	logCount, err = Rollback(pgxStore, migrations, RepoOrder{"delivery", "billing", "auth"}, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, logCount)
+
+	history, err := FetchHistory(pgxStore)
+	assert.NoError(t, err)
+	assert.Len(t, history, 14)
+	assert.Equal(t, sha1Checksum(migrations["auth"][0].Up), history[0].Checksum)
+
+	t.Run("failed migration is rolled back", func(t *testing.T) {
+		logCount, err := Migrate(pgxStore, Migrations{"auth": {migrations["auth"][0], {Up: `create table`}}}, RepoOrder{"auth"})
+		assert.Error(t, err)
+		assert.Equal(t, 0, logCount)
+		logs, err := pgxStore.fetchAllMigrationLogs()
+		assert.NoError(t, err)
+		assert.Empty(t, logs)
+	})
+}
@@ -4,7 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
-	"path/filepath"
+	"path"
 	"sort"
 	"strconv"
 	"strings"
@@ -23,12 +23,43 @@ import (
 //	0.create_users_table.down.sql
 //	1.add_username_column.up
 //	1.add_username_column.down.sql
+//
+// Use ReadDirWithOptions for other conventions.
 func ReadDir(fileSys fs.FS, path string) ([]Migration, error) {
+	return ReadDirWithOptions(fileSys, path, ReadDirOptions{})
+}
+
+// ReadDirWithOptions works like ReadDir, but allows for selecting
+// migration files conventions with ReadDirOptions.
+//
+// Examples of valid entries for ReadDirOptions{Versions: TimestampVersions, DotsInDescription: true}:
+//
+//	20230102150405.add_v1.2_columns.up.sql
+//	20230102150405.add_v1.2_columns.down.sql
+//	20230214093000.create_index.up.sql
+//
+// Examples of valid entries for ReadDirOptions{Layout: DirectoriesLayout, OptionalDown: true}:
+//
+//	0_create_users_table/up.sql
+//	0_create_users_table/down.sql
+//	1_drop_legacy_tables/up.sql
+func ReadDirWithOptions(fileSys fs.FS, path string, opts ReadDirOptions) ([]Migration, error) {
 	dirEntries, err := fs.ReadDir(fileSys, path)
 	if err != nil {
 		return nil, err
 	}
 
+	switch opts.Layout {
+	case DirectoriesLayout:
+		return readMigrationsDirs(fileSys, path, dirEntries, opts)
+	case FilesLayout:
+		return readMigrationsFiles(fileSys, path, dirEntries, opts)
+	}
+
+	return nil, errUnknownLayout
+}
+
+func readMigrationsFiles(fileSys fs.FS, dir string, dirEntries []fs.DirEntry, opts ReadDirOptions) ([]Migration, error) {
 	fileNames := make([]string, 0, len(dirEntries))
 	for _, dirEntry := range dirEntries {
 		if dirEntry.IsDir() {
@@ -37,13 +68,43 @@ func ReadDir(fileSys fs.FS, path string) ([]Migration, error) {
 		fileNames = append(fileNames, dirEntry.Name())
 	}
 
-	parsedFN, err := parseFileNames(fileNames)
+	parse := parseFileName
+	if opts.DotsInDescription {
+		parse = parseFileNameWithDots
+	}
+	parsedFN, err := parseFileNamesWith(fileNames, parse)
 	if err != nil {
 		return nil, err
 	}
 	var result []Migration
-	for i := 0; i+1 < len(parsedFN); i += 2 {
-		if parsedFN[i].idx != i/2 || parsedFN[i+1].idx != i/2 {
+	prevIdx := -1
+	for i := 0; i < len(parsedFN); {
+		upOnly := parsedFN[i].direction == up && (i+1 == len(parsedFN) || parsedFN[i+1].idx != parsedFN[i].idx)
+		if upOnly && opts.OptionalDown {
+			if !opts.Versions.follows(prevIdx, parsedFN[i].idx) {
+				return nil, errWithFileName{inner: errNotSequential, fileName: parsedFN[i].fileName}
+			}
+			upData, err := fs.ReadFile(fileSys, path.Join(dir, parsedFN[i].fileName))
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, Migration{
+				Description:  parsedFN[i].description,
+				Up:           string(upData),
+				Irreversible: true,
+				Version:      opts.Versions.version(parsedFN[i].idx),
+			})
+			prevIdx = parsedFN[i].idx
+			i++
+			continue
+		}
+		if i+1 == len(parsedFN) {
+			if parsedFN[i].direction == up {
+				return nil, errWithFileName{inner: errMissingDown, fileName: parsedFN[i].fileName}
+			}
+			return nil, errWithFileName{inner: errMissingUp, fileName: parsedFN[i].fileName}
+		}
+		if !opts.Versions.follows(prevIdx, parsedFN[i].idx) || parsedFN[i+1].idx != parsedFN[i].idx {
 			return nil, errWithFileName{inner: errNotSequential, fileName: parsedFN[i].fileName}
 		}
 		if parsedFN[i].description != parsedFN[i+1].description {
@@ -52,11 +113,11 @@ func ReadDir(fileSys fs.FS, path string) ([]Migration, error) {
 		if parsedFN[i].direction == parsedFN[i+1].direction {
 			return nil, errWithFileName{inner: errSameDirections, fileName: parsedFN[i].fileName}
 		}
-		iData, err := fs.ReadFile(fileSys, filepath.Join(path, parsedFN[i].fileName))
+		iData, err := fs.ReadFile(fileSys, path.Join(dir, parsedFN[i].fileName))
 		if err != nil {
 			return nil, err
 		}
-		iPlus1Data, err := fs.ReadFile(fileSys, filepath.Join(path, parsedFN[i+1].fileName))
+		iPlus1Data, err := fs.ReadFile(fileSys, path.Join(dir, parsedFN[i+1].fileName))
 		if err != nil {
 			return nil, err
 		}
@@ -64,16 +125,168 @@ func ReadDir(fileSys fs.FS, path string) ([]Migration, error) {
 			Description: parsedFN[i].description,
 			Up:          string(iData),
 			Down:        string(iPlus1Data),
+			Version:     opts.Versions.version(parsedFN[i].idx),
 		})
+		prevIdx = parsedFN[i].idx
+		i += 2
+	}
+
+	return result, nil
+}
+
+func readMigrationsDirs(fileSys fs.FS, dir string, dirEntries []fs.DirEntry, opts ReadDirOptions) ([]Migration, error) {
+	var parsedDN parsedFileNames
+	for _, dirEntry := range dirEntries {
+		if !dirEntry.IsDir() {
+			return nil, errWithFileName{inner: errContainsFile, fileName: dirEntry.Name()}
+		}
+		parsed, err := parseDirName(dirEntry.Name())
+		if err != nil {
+			return nil, errWithFileName{inner: err, fileName: dirEntry.Name()}
+		}
+		parsedDN = append(parsedDN, parsed)
 	}
+	sort.Sort(parsedDN)
 
+	result := make([]Migration, 0, len(parsedDN))
+	prevIdx := -1
+	for _, parsed := range parsedDN {
+		if !opts.Versions.follows(prevIdx, parsed.idx) {
+			return nil, errWithFileName{inner: errNotSequential, fileName: parsed.fileName}
+		}
+		migration, err := readMigrationDir(fileSys, path.Join(dir, parsed.fileName), opts)
+		if err != nil {
+			return nil, err
+		}
+		migration.Description = parsed.description
+		migration.Version = opts.Versions.version(parsed.idx)
+		result = append(result, *migration)
+		prevIdx = parsed.idx
+	}
+
+	if len(result) == 0 {
+		return nil, nil
+	}
 	return result, nil
 }
 
+func readMigrationDir(fileSys fs.FS, dir string, opts ReadDirOptions) (*Migration, error) {
+	dirEntries, err := fs.ReadDir(fileSys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var upFile, downFile string
+	for _, dirEntry := range dirEntries {
+		fileName := path.Join(dir, dirEntry.Name())
+		if dirEntry.IsDir() {
+			return nil, errWithFileName{inner: errContainsDirectory, fileName: fileName}
+		}
+		var found *string
+		switch direction(strings.SplitN(dirEntry.Name(), ".", 2)[0]) {
+		case up:
+			found = &upFile
+		case down:
+			found = &downFile
+		default:
+			return nil, errWithFileName{inner: errFileNameDirection, fileName: fileName}
+		}
+		if *found != "" {
+			return nil, errWithFileName{inner: errSameDirections, fileName: fileName}
+		}
+		*found = fileName
+	}
+	if upFile == "" {
+		return nil, errWithFileName{inner: errMissingUp, fileName: dir}
+	}
+	if downFile == "" && !opts.OptionalDown {
+		return nil, errWithFileName{inner: errMissingDown, fileName: dir}
+	}
+
+	upData, err := fs.ReadFile(fileSys, upFile)
+	if err != nil {
+		return nil, err
+	}
+	migration := Migration{Up: string(upData), Irreversible: downFile == ""}
+	if downFile != "" {
+		downData, err := fs.ReadFile(fileSys, downFile)
+		if err != nil {
+			return nil, err
+		}
+		migration.Down = string(downData)
+	}
+
+	return &migration, nil
+}
+
+// ReadDirOptions allows for selecting conventions used by ReadDirWithOptions.
+// Zero value represents conventions described in ReadDir.
+type ReadDirOptions struct {
+	// Versions determines which values of the first part of file name are valid.
+	Versions VersionScheme
+	// DotsInDescription allows description to contain dots. Direction is then
+	// the last "up" or "down" part of file name, e.g. 0.add_v1.2_columns.up.sql
+	DotsInDescription bool
+	// Layout determines whether every migration is stored in files or in its own directory.
+	Layout Layout
+	// OptionalDown allows for migrations without down file.
+	// Such migrations are marked as irreversible (see Migration.Irreversible).
+	OptionalDown bool
+}
+
+// VersionScheme determines which sequences of migrations' versions are valid.
+type VersionScheme int
+
+const (
+	// SequentialVersions requires versions to be incrementing ints starting from zero (0,1,2,3,..).
+	SequentialVersions VersionScheme = iota
+	// TimestampVersions requires versions to be increasing ints, gaps are allowed
+	// (e.g. 20230102150405, 20230214093000). Migrations are still applied under their position in repo,
+	// version is kept in Migration.Version. Migrate refuses to apply migrations when version was inserted
+	// before already applied migrations (e.g. branch with older timestamp merged after deploying newer one),
+	// such migration must get higher version.
+	TimestampVersions
+)
+
+func (v VersionScheme) follows(prevIdx, idx int) bool {
+	switch v {
+	case SequentialVersions:
+		return idx == prevIdx+1
+	case TimestampVersions:
+		return idx > prevIdx
+	}
+	return false
+}
+
+// version returns Migration.Version for given version from file name.
+func (v VersionScheme) version(idx int) int64 {
+	if v == TimestampVersions {
+		return int64(idx)
+	}
+	return 0
+}
+
+// Layout determines how migrations are stored in directory.
+type Layout int
+
+const (
+	// FilesLayout requires directory to contain files only (e.g. 0.create_users_table.up.sql).
+	FilesLayout Layout = iota
+	// DirectoriesLayout requires directory to contain one directory per migration.
+	// Directory name follows convention a_b (or a.b), where a is version, b is description.
+	// Directory contains "up" file and optionally (see ReadDirOptions.OptionalDown) "down" file,
+	// both with any extension (e.g. 0003_add_users/up.sql).
+	DirectoriesLayout
+)
+
 func parseFileNames(fileNames []string) (parsedFileNames, error) {
+	return parseFileNamesWith(fileNames, parseFileName)
+}
+
+func parseFileNamesWith(fileNames []string, parse func(fileName string) (*parsedFileName, error)) (parsedFileNames, error) {
 	var parsedFN parsedFileNames
 	for _, fileName := range fileNames {
-		parsed, err := parseFileName(fileName)
+		parsed, err := parse(fileName)
 		if err != nil {
 			return nil, err
 		}
@@ -103,6 +316,44 @@ func parseFileName(fileName string) (*parsedFileName, error) {
 	}, nil
 }
 
+func parseFileNameWithDots(fileName string) (*parsedFileName, error) {
+	divided := strings.Split(fileName, ".")
+	if len(divided) < 3 {
+		return nil, errFileNameParts
+	}
+	idx, err := strconv.Atoi(divided[0])
+	if err != nil {
+		return nil, errFileNameIdx
+	}
+	for i := len(divided) - 1; i > 1; i-- {
+		if divided[i] == string(up) || divided[i] == string(down) {
+			return &parsedFileName{
+				fileName:    fileName,
+				idx:         idx,
+				description: strings.Join(divided[1:i], "."),
+				direction:   direction(divided[i]),
+			}, nil
+		}
+	}
+	return nil, errFileNameDirection
+}
+
+func parseDirName(dirName string) (*parsedFileName, error) {
+	separatorIdx := strings.IndexAny(dirName, "_.")
+	if separatorIdx < 0 {
+		return nil, errDirNameParts
+	}
+	idx, err := strconv.Atoi(dirName[:separatorIdx])
+	if err != nil {
+		return nil, errFileNameIdx
+	}
+	return &parsedFileName{
+		fileName:    dirName,
+		idx:         idx,
+		description: dirName[separatorIdx+1:],
+	}, nil
+}
+
 func (a parsedFileNames) Len() int      { return len(a) }
 func (a parsedFileNames) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
 func (a parsedFileNames) Less(i, j int) bool {
@@ -136,6 +387,11 @@ var (
 	errNotSequential       = errors.New("index in file name is not sequential (every migration has up and down file?)")
 	errDescriptionNotEqual = errors.New("descriptions for migration differs")
 	errSameDirections      = errors.New("migration must have up and down files")
+	errContainsFile        = errors.New("migrations directory should contain directories only")
+	errDirNameParts        = errors.New("migration's directory name must contain at least 2 parts (idx_description)")
+	errMissingUp           = errors.New("migration must have up file")
+	errMissingDown         = errors.New("migration must have down file (set ReadDirOptions.OptionalDown for migrations without down file)")
+	errUnknownLayout       = errors.New("unknown migrations directory layout")
 )
 
 func (e errWithFileName) Error() string {
@@ -47,12 +47,12 @@ func TestReadDir(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, []Migration(nil), migrations)
 	})
-	t.Run("returns empty array when dir contains one file", func(t *testing.T) {
+	t.Run("returns error when dir contains one file", func(t *testing.T) {
 		fileSys := fstest.MapFS{
 			"one_file/0.description.up": {},
 		}
 		migrations, err := ReadDir(fileSys, "one_file")
-		assert.NoError(t, err)
+		assert.Equal(t, errWithFileName{inner: errMissingDown, fileName: "0.description.up"}, err)
 		assert.Equal(t, []Migration(nil), migrations)
 	})
 	t.Run("returns error when dir contains dir", func(t *testing.T) {
@@ -63,15 +63,20 @@ func TestReadDir(t *testing.T) {
 		assert.EqualError(t, err, errContainsDirectory.Error())
 		assert.Equal(t, []Migration(nil), migrations)
 	})
-	t.Run("skips last migration with missing direction", func(t *testing.T) {
+	t.Run("returns error when last migration's direction file is missing", func(t *testing.T) {
 		fileSys := fstest.MapFS{
 			"0.description.up":   {},
 			"0.description.down": {},
 			"1.description.down": {},
 		}
 		migrations, err := ReadDir(fileSys, ".")
-		assert.NoError(t, err)
-		assert.Len(t, migrations, 1)
+		assert.Equal(t, errWithFileName{inner: errMissingUp, fileName: "1.description.down"}, err)
+		assert.Equal(t, []Migration(nil), migrations)
+
+		// # Down file can't be optional for migration without up file
+		migrations, err = ReadDirWithOptions(fileSys, ".", ReadDirOptions{OptionalDown: true})
+		assert.Equal(t, errWithFileName{inner: errMissingUp, fileName: "1.description.down"}, err)
+		assert.Equal(t, []Migration(nil), migrations)
 	})
 	t.Run("returns error when migration's direction file is missing", func(t *testing.T) {
 		fileSys := fstest.MapFS{
@@ -150,6 +155,124 @@ func TestReadDir(t *testing.T) {
 
 }
 
+func TestReadDirWithOptions(t *testing.T) {
+	t.Run("timestamp versions with dots in description", func(t *testing.T) {
+		fileSys := fstest.MapFS{
+			"20230102150405.add_v1.2_columns.up.sql":   {Data: []byte("up 0")},
+			"20230102150405.add_v1.2_columns.down.sql": {Data: []byte("down 0")},
+			"20230214093000.create.up.index.up.sql":    {Data: []byte("up 1")},
+			"20230214093000.create.up.index.down.sql":  {Data: []byte("down 1")},
+		}
+		migrations, err := ReadDirWithOptions(fileSys, ".", ReadDirOptions{Versions: TimestampVersions, DotsInDescription: true})
+		assert.NoError(t, err)
+		assert.Equal(t, []Migration{
+			{Description: "add_v1.2_columns", Up: "up 0", Down: "down 0", Version: 20230102150405},
+			{Description: "create.up.index", Up: "up 1", Down: "down 1", Version: 20230214093000},
+		}, migrations)
+	})
+	t.Run("timestamp versions can not repeat", func(t *testing.T) {
+		fileSys := fstest.MapFS{
+			"20230102150405.a.up":   {},
+			"20230102150405.a.down": {},
+			"20230102150405.b.up":   {},
+			"20230102150405.b.down": {},
+		}
+		_, err := ReadDirWithOptions(fileSys, ".", ReadDirOptions{Versions: TimestampVersions})
+		assert.Error(t, err)
+	})
+	t.Run("optional down files", func(t *testing.T) {
+		fileSys := fstest.MapFS{
+			"0.create.up":   {Data: []byte("up 0")},
+			"0.create.down": {Data: []byte("down 0")},
+			"1.drop.up":     {Data: []byte("up 1")},
+			"2.alter.up":    {Data: []byte("up 2")},
+			"2.alter.down":  {Data: []byte("down 2")},
+		}
+		migrations, err := ReadDirWithOptions(fileSys, ".", ReadDirOptions{OptionalDown: true})
+		assert.NoError(t, err)
+		assert.Equal(t, []Migration{
+			{Description: "create", Up: "up 0", Down: "down 0"},
+			{Description: "drop", Up: "up 1", Irreversible: true},
+			{Description: "alter", Up: "up 2", Down: "down 2"},
+		}, migrations)
+	})
+	t.Run("optional down files still require up file", func(t *testing.T) {
+		fileSys := fstest.MapFS{
+			"0.create.up":   {},
+			"0.create.down": {},
+			"1.drop.down":   {},
+			"2.alter.up":    {},
+		}
+		_, err := ReadDirWithOptions(fileSys, ".", ReadDirOptions{OptionalDown: true})
+		assert.EqualError(t, err, errWithFileName{inner: errNotSequential, fileName: "1.drop.down"}.Error())
+	})
+	t.Run("directories layout", func(t *testing.T) {
+		fileSys := fstest.MapFS{
+			"migrations/0000_create_users/up.sql":   {Data: []byte("up 0")},
+			"migrations/0000_create_users/down.sql": {Data: []byte("down 0")},
+			"migrations/0001.add_v1.2_columns/up":   {Data: []byte("up 1")},
+			"migrations/0002_drop_legacy/up.sql":    {Data: []byte("up 2")},
+		}
+		migrations, err := ReadDirWithOptions(fileSys, "migrations", ReadDirOptions{Layout: DirectoriesLayout, OptionalDown: true})
+		assert.NoError(t, err)
+		assert.Equal(t, []Migration{
+			{Description: "create_users", Up: "up 0", Down: "down 0"},
+			{Description: "add_v1.2_columns", Up: "up 1", Irreversible: true},
+			{Description: "drop_legacy", Up: "up 2", Irreversible: true},
+		}, migrations)
+	})
+	t.Run("directories layout errors", func(t *testing.T) {
+		caseTable := []struct {
+			name        string
+			fileSys     fstest.MapFS
+			errExpected error
+		}{
+			{
+				name:        "contains file",
+				fileSys:     fstest.MapFS{"0.create.up": {}},
+				errExpected: errWithFileName{inner: errContainsFile, fileName: "0.create.up"},
+			},
+			{
+				name:        "invalid directory name",
+				fileSys:     fstest.MapFS{"create/up.sql": {}},
+				errExpected: errWithFileName{inner: errDirNameParts, fileName: "create"},
+			},
+			{
+				name:        "not sequential",
+				fileSys:     fstest.MapFS{"0_a/up": {}, "0_a/down": {}, "2_b/up": {}, "2_b/down": {}},
+				errExpected: errWithFileName{inner: errNotSequential, fileName: "2_b"},
+			},
+			{
+				name:        "missing up",
+				fileSys:     fstest.MapFS{"0_a/down": {}},
+				errExpected: errWithFileName{inner: errMissingUp, fileName: "0_a"},
+			},
+			{
+				name:        "missing down",
+				fileSys:     fstest.MapFS{"0_a/up": {}},
+				errExpected: errWithFileName{inner: errMissingDown, fileName: "0_a"},
+			},
+			{
+				name:        "same directions",
+				fileSys:     fstest.MapFS{"0_a/up": {}, "0_a/up.sql": {}, "0_a/down": {}},
+				errExpected: errWithFileName{inner: errSameDirections, fileName: "0_a/up.sql"},
+			},
+			{
+				name:        "invalid direction",
+				fileSys:     fstest.MapFS{"0_a/UP": {}},
+				errExpected: errWithFileName{inner: errFileNameDirection, fileName: "0_a/UP"},
+			},
+		}
+		for _, testCase := range caseTable {
+			t.Run(testCase.name, func(t *testing.T) {
+				migrations, err := ReadDirWithOptions(testCase.fileSys, ".", ReadDirOptions{Layout: DirectoriesLayout})
+				assert.EqualError(t, err, testCase.errExpected.Error())
+				assert.Nil(t, migrations)
+			})
+		}
+	})
+}
+
 func TestParseFileNames(t *testing.T) {
 	t.Run("empty", func(t *testing.T) {
 		res, err := parseFileNames([]string{})
@@ -198,6 +321,19 @@ func TestParseFileName(t *testing.T) {
 	})
 }
 
+func TestParseFileNameWithDots(t *testing.T) {
+	t.Run("valid file name", func(t *testing.T) {
+		res, err := parseFileNameWithDots("0.add_v1.2_columns.up.sql")
+		assert.NoError(t, err)
+		assert.Equal(t, &parsedFileName{fileName: "0.add_v1.2_columns.up.sql", idx: 0, description: "add_v1.2_columns", direction: up}, res)
+	})
+	t.Run("missing direction", func(t *testing.T) {
+		res, err := parseFileNameWithDots("0.add_v1.2_columns.sql")
+		assert.Nil(t, res)
+		assert.EqualError(t, err, errFileNameDirection.Error())
+	})
+}
+
 type fileSysMock struct {
 	wrapped        fs.FS
 	brokenFileName string
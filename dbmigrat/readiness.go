@@ -0,0 +1,90 @@
+package dbmigrat
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CheckReady returns *NotReadyError when database has pending migrations of repos from repoOrder
+// or when migrations log of these repos is corrupted (see CheckLogTableIntegrity).
+// Repos not present in repoOrder are not checked, so a service can check only repos it depends on.
+// Migrations from PhaseContract are not considered pending, so new code is ready after applying
+// migrations from PhaseExpand (contract migrations are applied after it is deployed).
+// Conditional migrations (see Migration.Environments) are pending only when their conditions are met,
+// use CheckReadyWithOptions for passing environment and flags.
+//
+// CheckReady can be called before starting a service or as a readiness probe.
+func CheckReady(s store, migrations Migrations, repoOrder RepoOrder) error {
+	return CheckReadyWithOptions(s, migrations, repoOrder, ReadyOptions{})
+}
+
+// CheckReadyWithOptions works like CheckReady, but evaluates conditions of migrations
+// against environment and flags from opts (the ones passed to MigrateWithOptions).
+func CheckReadyWithOptions(s store, migrations Migrations, repoOrder RepoOrder, opts ReadyOptions) error {
+	status, err := FetchStatus(s, migrations, repoOrder)
+	if err != nil {
+		return err
+	}
+	integrity, err := CheckLogTableIntegrity(s, migrations)
+	if err != nil {
+		return err
+	}
+
+	conditions := conditionsEvaluator{s: s, opts: MigrateOptions{Environment: opts.Environment, Flags: opts.Flags}}
+	checked := map[Repo]bool{}
+	notReady := &NotReadyError{Pending: map[Repo][]PendingMigration{}}
+	for _, repo := range repoOrder {
+		checked[repo] = true
+		if len(integrity.RedundantMigrations[repo]) > 0 || len(integrity.InvalidChecksums[repo]) > 0 {
+			notReady.Corrupted = append(notReady.Corrupted, repo)
+		}
+	}
+	for _, repoStatus := range status.Repos {
+		if !checked[repoStatus.Repo] {
+			continue
+		}
+		for _, pending := range repoStatus.Pending {
+			migration := migrations[repoStatus.Repo][pending.Idx]
+			if migration.phase() == PhaseContract {
+				continue
+			}
+			met, err := conditions.met(migration)
+			if err != nil {
+				return err
+			}
+			if met {
+				notReady.Pending[repoStatus.Repo] = append(notReady.Pending[repoStatus.Repo], pending)
+			}
+		}
+	}
+	if len(notReady.Pending) > 0 || len(notReady.Corrupted) > 0 {
+		return notReady
+	}
+
+	return nil
+}
+
+// ReadyOptions allows for configuring CheckReadyWithOptions.
+type ReadyOptions struct {
+	// Environment in which migrations are applied (see MigrateOptions.Environment).
+	Environment string
+	// Flags enabled when applying migrations (see MigrateOptions.Flags).
+	Flags []string
+}
+
+// NotReadyError is returned by CheckReady.
+type NotReadyError struct {
+	Pending   map[Repo][]PendingMigration
+	Corrupted []Repo
+}
+
+func (e *NotReadyError) Error() string {
+	var problems []string
+	for _, repo := range sortedRepos(e.Pending) {
+		problems = append(problems, fmt.Sprintf("%d pending migrations in %s", len(e.Pending[repo]), repo))
+	}
+	for _, repo := range e.Corrupted {
+		problems = append(problems, fmt.Sprintf("corrupted migrations log of %s", repo))
+	}
+	return "database is not ready: " + strings.Join(problems, ", ")
+}
@@ -0,0 +1,83 @@
+package dbmigrat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckReady(t *testing.T) {
+	assert.NoError(t, th.resetDB())
+	assert.NoError(t, th.pgStore.CreateLogTable())
+	_, err := Migrate(th.pgStore, th.migrations1, RepoOrder{"auth", "billing"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, CheckReady(th.pgStore, th.migrations2, RepoOrder{"auth"}))
+
+	err = CheckReady(th.pgStore, th.migrations2, RepoOrder{"auth", "billing", "delivery"})
+	var notReady *NotReadyError
+	assert.ErrorAs(t, err, &notReady)
+	assert.Equal(t, map[Repo][]PendingMigration{
+		"billing":  {{Idx: 1, Description: "add value gross column"}},
+		"delivery": {{Idx: 0, Description: "create delivery status table"}},
+	}, notReady.Pending)
+	assert.EqualError(t, err, "database is not ready: 1 pending migrations in billing, 1 pending migrations in delivery")
+
+	// # Conditional migrations are pending only when their conditions are met
+	conditional := Migrations{"auth": append(th.migrations1["auth"][:2:2], Migration{Up: `select 1`, Environments: []string{"dev"}})}
+	assert.NoError(t, CheckReady(th.pgStore, conditional, RepoOrder{"auth"}))
+	assert.NoError(t, CheckReadyWithOptions(th.pgStore, conditional, RepoOrder{"auth"}, ReadyOptions{Environment: "prod"}))
+	err = CheckReadyWithOptions(th.pgStore, conditional, RepoOrder{"auth"}, ReadyOptions{Environment: "dev"})
+	assert.ErrorAs(t, err, &notReady)
+	assert.Equal(t, map[Repo][]PendingMigration{"auth": {{Idx: 2}}}, notReady.Pending)
+
+	corrupted := Migrations{"auth": {{Up: `create table other_users (id serial primary key)`}}}
+	err = CheckReady(th.pgStore, corrupted, RepoOrder{"auth"})
+	assert.ErrorAs(t, err, &notReady)
+	assert.Equal(t, []Repo{"auth"}, notReady.Corrupted)
+
+	t.Run("db error", func(t *testing.T) {
+		err := CheckReady(errorStoreMock{wrapped: th.pgStore, errFetchAllMigrationLogs: true}, th.migrations1, RepoOrder{"auth"})
+		assert.EqualError(t, err, exampleErr.Error())
+	})
+}
+
+func TestCheckReadyConditions(t *testing.T) {
+	s := &MemoryStore{Extensions: map[string]bool{"postgis": true}}
+	migrations := Migrations{"auth": {
+		{Up: `create table users ()`, Down: `drop table users`},
+		{Up: `insert into users default values`, Flags: []string{"seed"}},
+		{Up: `create extension postgis`, Extensions: []string{"postgis"}},
+		{Up: `create extension pg_trgm`, Extensions: []string{"pg_trgm"}},
+	}}
+	_, err := Migrate(s, Migrations{"auth": migrations["auth"][:1]}, RepoOrder{"auth"})
+	assert.NoError(t, err)
+
+	err = CheckReady(s, migrations, RepoOrder{"auth"})
+	var notReady *NotReadyError
+	assert.ErrorAs(t, err, &notReady)
+	assert.Equal(t, map[Repo][]PendingMigration{"auth": {{Idx: 2}}}, notReady.Pending)
+
+	err = CheckReadyWithOptions(s, migrations, RepoOrder{"auth"}, ReadyOptions{Flags: []string{"seed"}})
+	assert.ErrorAs(t, err, &notReady)
+	assert.Equal(t, map[Repo][]PendingMigration{"auth": {{Idx: 1}, {Idx: 2}}}, notReady.Pending)
+
+	// # Migrations with met conditions are applied by Migrate, others stay pending but don't block readiness
+	_, err = MigrateWithOptions(s, migrations, RepoOrder{"auth"}, MigrateOptions{Flags: []string{"seed"}})
+	assert.NoError(t, err)
+	assert.NoError(t, CheckReadyWithOptions(s, migrations, RepoOrder{"auth"}, ReadyOptions{Flags: []string{"seed"}}))
+}
+
+func TestCheckReadyPhases(t *testing.T) {
+	s := &MemoryStore{}
+	migrations := Migrations{"auth": {
+		{Up: `alter table users add column username text`, Phase: PhaseExpand},
+		{Up: `alter table users drop column login`, Phase: PhaseContract},
+	}}
+	assert.Error(t, CheckReady(s, migrations, RepoOrder{"auth"}))
+
+	// # New code is ready after expand, before contract
+	_, err := MigrateWithOptions(s, migrations, RepoOrder{"auth"}, MigrateOptions{Phase: PhaseExpand})
+	assert.NoError(t, err)
+	assert.NoError(t, CheckReady(s, migrations, RepoOrder{"auth"}))
+}
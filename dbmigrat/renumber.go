@@ -0,0 +1,103 @@
+package dbmigrat
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// RenumberMigrationFiles resolves indexes conflicts in directory under dirPath (following ReadDir convention).
+// Migrations with given descriptions (e.g. added in not yet merged branch) get indexes
+// after the last index of remaining migrations (e.g. already merged into main branch).
+// Moved migrations keep their relative order.
+//
+// It returns paths of renamed files, mapped from old to new path.
+//
+// Example:
+//
+//	0.a.up.sql, 0.a.down.sql, 1.b.up.sql, 1.b.down.sql, 1.c.up.sql, 1.c.down.sql
+//
+// after RenumberMigrationFiles(dirPath, "b"):
+//
+//	0.a.up.sql, 0.a.down.sql, 1.c.up.sql, 1.c.down.sql, 2.b.up.sql, 2.b.down.sql
+func RenumberMigrationFiles(dirPath string, descriptions ...string) (map[string]string, error) {
+	dirEntries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	fileNames := make([]string, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() {
+			return nil, errContainsDirectory
+		}
+		fileNames = append(fileNames, dirEntry.Name())
+	}
+	parsedFN, err := parseFileNames(fileNames)
+	if err != nil {
+		return nil, err
+	}
+
+	toMove := map[string]bool{}
+	for _, description := range descriptions {
+		toMove[description] = true
+	}
+	var remainingFileNames []string
+	var movedFN parsedFileNames
+	movedDirections := map[string]map[direction]int{}
+	for _, parsed := range parsedFN {
+		if !toMove[parsed.description] {
+			remainingFileNames = append(remainingFileNames, parsed.fileName)
+			continue
+		}
+		movedFN = append(movedFN, parsed)
+		if movedDirections[parsed.description] == nil {
+			movedDirections[parsed.description] = map[direction]int{}
+		}
+		movedDirections[parsed.description][parsed.direction]++
+	}
+	for description := range toMove {
+		directions, ok := movedDirections[description]
+		if !ok {
+			return nil, fmt.Errorf("%w (%s)", errRenumberNotFound, description)
+		}
+		if directions[up] != 1 || directions[down] != 1 {
+			return nil, fmt.Errorf("%w (%s)", errSameDirections, description)
+		}
+	}
+
+	nextIdx, err := nextMigrationIdx(remainingFileNames)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(movedFN, func(i, j int) bool {
+		if movedFN[i].idx == movedFN[j].idx {
+			return movedFN[i].description < movedFN[j].description
+		}
+		return movedFN[i].idx < movedFN[j].idx
+	})
+	renamed := map[string]string{}
+	newIndexes := map[string]int{}
+	for _, parsed := range movedFN {
+		newIdx, ok := newIndexes[parsed.description]
+		if !ok {
+			newIdx = nextIdx
+			newIndexes[parsed.description] = newIdx
+			nextIdx++
+		}
+		oldPath := filepath.Join(dirPath, parsed.fileName)
+		newPath := filepath.Join(dirPath, fmt.Sprintf("%d%s", newIdx, parsed.fileName[strings.IndexByte(parsed.fileName, '.'):]))
+		err = os.Rename(oldPath, newPath)
+		if err != nil {
+			return renamed, err
+		}
+		renamed[oldPath] = newPath
+	}
+
+	return renamed, nil
+}
+
+var errRenumberNotFound = errors.New("migration with given description not found")
@@ -0,0 +1,50 @@
+package dbmigrat
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenumberMigrationFiles(t *testing.T) {
+	t.Run("moves migrations from unmerged branch after remaining ones", func(t *testing.T) {
+		dir := createFiles(t,
+			"0.a.up.sql", "0.a.down.sql",
+			"1.main.up.sql", "1.main.down.sql",
+			"1.branch.up.sql", "1.branch.down.sql",
+			"2.main_next.up.sql", "2.main_next.down.sql",
+			"2.branch_next.up.sql", "2.branch_next.down.sql",
+		)
+		renamed, err := RenumberMigrationFiles(dir, "branch_next", "branch")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			filepath.Join(dir, "1.branch.up.sql"):        filepath.Join(dir, "3.branch.up.sql"),
+			filepath.Join(dir, "1.branch.down.sql"):      filepath.Join(dir, "3.branch.down.sql"),
+			filepath.Join(dir, "2.branch_next.up.sql"):   filepath.Join(dir, "4.branch_next.up.sql"),
+			filepath.Join(dir, "2.branch_next.down.sql"): filepath.Join(dir, "4.branch_next.down.sql"),
+		}, renamed)
+
+		migrations, err := ReadDir(os.DirFS(dir), ".")
+		assert.NoError(t, err)
+		var descriptions []string
+		for _, migration := range migrations {
+			descriptions = append(descriptions, migration.Description)
+		}
+		assert.Equal(t, []string{"a", "main", "main_next", "branch", "branch_next"}, descriptions)
+		assert.Equal(t, "2.branch_next.up.sql", migrations[4].Up)
+	})
+
+	t.Run("returns error for unknown description", func(t *testing.T) {
+		dir := createFiles(t, "0.a.up.sql", "0.a.down.sql")
+		_, err := RenumberMigrationFiles(dir, "b")
+		assert.EqualError(t, err, errRenumberNotFound.Error()+" (b)")
+	})
+
+	t.Run("returns error when remaining migrations still conflict", func(t *testing.T) {
+		dir := createFiles(t, "0.a.up", "0.a.down", "0.b.up", "0.b.down", "0.c.up", "0.c.down")
+		_, err := RenumberMigrationFiles(dir, "c")
+		assert.ErrorIs(t, err, errIndexConflict)
+	})
+}
@@ -0,0 +1,132 @@
+package dbmigrat
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+)
+
+// ReadRepeatableDir is helper func which allows for reading repeatable migrations from directory.
+// Directory under provided path must contain files only. Name of repeatable migration
+// is file name without extension, files are sorted by name.
+//
+// Examples of valid files names:
+//
+//	active_users_view.sql
+//	calculate_total_function.sql
+func ReadRepeatableDir(fileSys fs.FS, dir string) ([]RepeatableMigration, error) {
+	dirEntries, err := fs.ReadDir(fileSys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]RepeatableMigration, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() {
+			return nil, errContainsDirectory
+		}
+		up, err := fs.ReadFile(fileSys, path.Join(dir, dirEntry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, RepeatableMigration{
+			Name: strings.TrimSuffix(dirEntry.Name(), path.Ext(dirEntry.Name())),
+			Up:   string(up),
+		})
+	}
+
+	return result, nil
+}
+
+// migrateRepeatable applies repeatable migrations which were not applied yet or which checksum has changed.
+// Repeatable migrations of repo are skipped when opts limit applied versioned migrations
+// of that repo (see MigrateOptions.Repeatable).
+func migrateRepeatable(s store, migrations Migrations, repoOrder RepoOrder, migrationSerial int, opts MigrateOptions) (int, error) {
+	if len(opts.Repeatable) == 0 || opts.Phase == PhaseExpand {
+		return 0, nil
+	}
+	logs, err := s.fetchRepeatableLogs()
+	if err != nil {
+		return 0, err
+	}
+	appliedChecksums := map[Repo]map[string]string{}
+	for _, log := range logs {
+		if appliedChecksums[log.Repo] == nil {
+			appliedChecksums[log.Repo] = map[string]string{}
+		}
+		appliedChecksums[log.Repo][log.Name] = log.Checksum
+	}
+
+	var appliedCount int
+	for _, orderedRepo := range repoOrder {
+		if opts.Target != nil {
+			targetIdx, ok := opts.Target[orderedRepo]
+			if !ok || targetIdx != len(migrations[orderedRepo])-1 {
+				continue
+			}
+		}
+		for _, migration := range opts.Repeatable[orderedRepo] {
+			checksum := sha1Checksum(migration.Up)
+			if appliedChecksums[orderedRepo][migration.Name] == checksum {
+				continue
+			}
+			log := MigrationLog{
+				Idx:             repeatableIdx,
+				Repo:            orderedRepo,
+				MigrationSerial: migrationSerial,
+				Checksum:        checksum,
+				Description:     migration.Name,
+			}
+			err = execMigration(s, migration.Up, &log, HistoryApply, opts.Actor)
+			if err != nil {
+				return 0, fmt.Errorf("%w (repeatable migration %s in repo %s)", err, migration.Name, orderedRepo)
+			}
+			err = s.upsertRepeatableLog(RepeatableLog{Repo: orderedRepo, Name: migration.Name, Checksum: checksum})
+			if err != nil {
+				return 0, err
+			}
+			err = s.insertHistory(newHistoryEntries([]MigrationLog{log}, HistoryApply, opts.Actor))
+			if err != nil {
+				return 0, err
+			}
+			appliedCount++
+		}
+	}
+
+	return appliedCount, nil
+}
+
+func (m RepeatableMigrations) validate() error {
+	for repo, repoMigrations := range m {
+		names := map[string]bool{}
+		for _, migration := range repoMigrations {
+			if names[migration.Name] {
+				return fmt.Errorf("%w (%s, %s)", errDuplicatedRepeatable, repo, migration.Name)
+			}
+			names[migration.Name] = true
+		}
+	}
+	return nil
+}
+
+type RepeatableMigrations map[Repo][]RepeatableMigration
+
+// repeatableIdx is Idx of history entries recording repeatable migrations.
+const repeatableIdx = -1
+
+// RepeatableMigration is applied again whenever its Up changes, e.g. redefinition of view or function
+// ("create or replace view ..."). Repeatable migrations are saved in log by name and are never rolled back.
+type RepeatableMigration struct {
+	Name string
+	Up   string
+}
+
+// RepeatableLog represents repeatable migration saved in repeatable migrations log table.
+type RepeatableLog struct {
+	Repo      Repo      `json:"repo"`
+	Name      string    `json:"name"`
+	Checksum  string    `json:"checksum"`
+	AppliedAt time.Time `db:"applied_at" json:"appliedAt"`
+}
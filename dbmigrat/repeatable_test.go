@@ -0,0 +1,121 @@
+package dbmigrat
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadRepeatableDir(t *testing.T) {
+	fileSys := fstest.MapFS{
+		"repeatable/users_view.sql":     {Data: []byte("create or replace view users_view as select 1")},
+		"repeatable/total_function.sql": {Data: []byte("create or replace function total() ...")},
+	}
+	migrations, err := ReadRepeatableDir(fileSys, "repeatable")
+	assert.NoError(t, err)
+	assert.Equal(t, []RepeatableMigration{
+		{Name: "total_function", Up: "create or replace function total() ..."},
+		{Name: "users_view", Up: "create or replace view users_view as select 1"},
+	}, migrations)
+
+	t.Run("returns error for directory", func(t *testing.T) {
+		_, err := ReadRepeatableDir(fstest.MapFS{"repeatable/nested/view.sql": {}}, "repeatable")
+		assert.ErrorIs(t, err, errContainsDirectory)
+	})
+}
+
+func TestMigrateRepeatable(t *testing.T) {
+	assert.NoError(t, th.resetDB())
+	assert.NoError(t, th.pgStore.CreateLogTable())
+	repeatable := RepeatableMigrations{
+		"auth": {{Name: "users_count", Up: `create or replace view users_count as select count(*) as count from users`}},
+	}
+
+	logCount, err := MigrateWithOptions(th.pgStore, th.migrations1, RepoOrder{"auth", "billing"}, MigrateOptions{Repeatable: repeatable})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, logCount)
+
+	// # Unchanged repeatable migration is not applied again
+	logCount, err = MigrateWithOptions(th.pgStore, th.migrations1, RepoOrder{"auth", "billing"}, MigrateOptions{Repeatable: repeatable})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, logCount)
+
+	repeatable["auth"][0].Up = `create or replace view users_count as select count(*) as count, max(id) as max_id from users`
+	logCount, err = MigrateWithOptions(th.pgStore, th.migrations1, RepoOrder{"auth", "billing"}, MigrateOptions{Repeatable: repeatable})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, logCount)
+	_, err = th.db.Exec(`select max_id from users_count`)
+	assert.NoError(t, err)
+
+	var logs []RepeatableLog
+	assert.NoError(t, th.db.Select(&logs, `select * from dbmigrat_repeatable_log`))
+	assert.Len(t, logs, 1)
+	assert.Equal(t, sha1Checksum(repeatable["auth"][0].Up), logs[0].Checksum)
+
+	t.Run("duplicated name", func(t *testing.T) {
+		duplicated := RepeatableMigrations{"auth": {{Name: "view"}, {Name: "view"}}}
+		_, err := MigrateWithOptions(th.pgStore, th.migrations1, RepoOrder{"auth", "billing"}, MigrateOptions{Repeatable: duplicated})
+		assert.ErrorIs(t, err, errDuplicatedRepeatable)
+	})
+
+	t.Run("store errors", func(t *testing.T) {
+		repeatable["auth"][0].Up = `create or replace view users_count as select count(*) as count from users`
+		for _, storeMock := range []errorStoreMock{
+			{wrapped: th.pgStore, errFetchRepeatableLogs: true},
+			{wrapped: th.pgStore, errUpsertRepeatableLog: true},
+		} {
+			logCount, err := MigrateWithOptions(storeMock, th.migrations1, RepoOrder{"auth", "billing"}, MigrateOptions{Repeatable: repeatable})
+			assert.EqualError(t, err, exampleMultiErr.Error())
+			assert.Equal(t, 0, logCount)
+		}
+	})
+}
+
+func TestMigrateRepeatableOptions(t *testing.T) {
+	s := &MemoryStore{}
+	repoOrder := RepoOrder{"auth", "billing"}
+	view := `create or replace view users_count as select count(*) as count from users`
+	repeatable := RepeatableMigrations{"auth": {{Name: "users_count", Up: view}}}
+
+	// # Repeatable migrations wait for the whole schema of repo
+	logCount, err := MigrateWithOptions(s, th.migrations1, repoOrder, MigrateOptions{Repeatable: repeatable, Phase: PhaseExpand})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, logCount)
+	assert.NotContains(t, s.Executed, view)
+
+	s = &MemoryStore{}
+	logCount, err = MigrateWithOptions(s, th.migrations1, repoOrder, MigrateOptions{Repeatable: repeatable, Target: Target{"auth": 0, "billing": 0}})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, logCount)
+	assert.NotContains(t, s.Executed, view)
+
+	logCount, err = MigrateWithOptions(s, th.migrations1, repoOrder, MigrateOptions{Repeatable: repeatable, Target: Target{"auth": 1}, Actor: "ci"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, logCount)
+	assert.Equal(t, view, s.Executed[len(s.Executed)-1])
+
+	history, err := FetchHistory(s)
+	assert.NoError(t, err)
+	entry := history[len(history)-1]
+	assert.Equal(t, repeatableIdx, entry.Idx)
+	assert.Equal(t, Repo("auth"), entry.Repo)
+	assert.Equal(t, "users_count", entry.Description)
+	assert.Equal(t, sha1Checksum(view), entry.Checksum)
+	assert.Equal(t, HistoryApply, entry.Action)
+	assert.Equal(t, HistorySuccess, entry.Outcome)
+	assert.Equal(t, "ci", entry.Actor)
+	assert.Equal(t, history[len(history)-2].MigrationSerial, entry.MigrationSerial)
+
+	// # Failed repeatable migration is saved in history
+	repeatable["auth"][0].Up = view + ` where true`
+	s.ExecFunc = func(query string) (int64, error) {
+		return 0, exampleErr
+	}
+	_, err = MigrateWithOptions(s, th.migrations1, repoOrder, MigrateOptions{Repeatable: repeatable})
+	assert.ErrorIs(t, err, exampleErr)
+	history, err = FetchHistory(s)
+	assert.NoError(t, err)
+	assert.Equal(t, HistoryFailure, history[len(history)-1].Outcome)
+	assert.Equal(t, "users_count", history[len(history)-1].Description)
+}
@@ -0,0 +1,148 @@
+package dbmigrat
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VerifyRoundTrip checks whether Down of every pending migration reverses its Up.
+// For every pending migration (in given repoOrder) it takes a snapshot of database schema,
+// applies Up, applies Down, compares schema with the snapshot and applies Up again.
+// It returns count of verified migrations.
+//
+// It is meant to be run against a scratch database (e.g. schema created by dbmigrattest.New),
+// as statements are executed without transaction and verified migrations stay applied.
+// Irreversible migrations are applied without verification.
+//
+// schemaStore is a store able to snapshot database schema, implemented by PostgresStore and PgxStore.
+//
+// When Down does not reverse Up, verification stops and returned error is *RoundTripError.
+func VerifyRoundTrip(s schemaStore, migrations Migrations, repoOrder RepoOrder) (int, error) {
+	lastMigrationSerial, err := s.fetchLastMigrationSerial()
+	if err != nil {
+		return 0, err
+	}
+	lastMigrationIndexes, err := s.fetchLastMigrationIndexes()
+	if err != nil {
+		return 0, err
+	}
+
+	var verifiedCount int
+	for _, orderedRepo := range repoOrder {
+		lastMigrationIdx, ok := lastMigrationIndexes[orderedRepo]
+		if !ok {
+			lastMigrationIdx = -1
+		}
+		for idx := lastMigrationIdx + 1; idx < len(migrations[orderedRepo]); idx++ {
+			migrationToVerify := migrations[orderedRepo][idx]
+			log := MigrationLog{
+				Idx:             idx,
+				Repo:            orderedRepo,
+				MigrationSerial: lastMigrationSerial + 1,
+				Checksum:        sha1Checksum(migrationToVerify.Up),
+				Description:     migrationToVerify.Description,
+			}
+			if !migrationToVerify.Irreversible {
+				err = verifyRoundTrip(s, migrationToVerify, log)
+				if err != nil {
+					return verifiedCount, err
+				}
+				verifiedCount++
+			}
+			_, err = s.exec(migrationToVerify.Up)
+			if err != nil {
+				return verifiedCount, roundTripStepError(log, up, err)
+			}
+			err = insertLogs(s, []MigrationLog{log}, "")
+			if err != nil {
+				return verifiedCount, err
+			}
+		}
+	}
+
+	return verifiedCount, nil
+}
+
+func verifyRoundTrip(s schemaStore, migrationToVerify Migration, log MigrationLog) error {
+	before, err := s.fetchSchemaSnapshot()
+	if err != nil {
+		return err
+	}
+	_, err = s.exec(migrationToVerify.Up)
+	if err != nil {
+		return roundTripStepError(log, up, err)
+	}
+	_, err = s.exec(migrationToVerify.Down)
+	if err != nil {
+		return roundTripStepError(log, down, err)
+	}
+	after, err := s.fetchSchemaSnapshot()
+	if err != nil {
+		return err
+	}
+
+	missing, redundant := diffSnapshots(before, after)
+	if len(missing) > 0 || len(redundant) > 0 {
+		return &RoundTripError{
+			Repo:        log.Repo,
+			Idx:         log.Idx,
+			Description: log.Description,
+			Missing:     missing,
+			Redundant:   redundant,
+		}
+	}
+	return nil
+}
+
+func diffSnapshots(before, after []string) (missing []string, redundant []string) {
+	afterSet := map[string]bool{}
+	for _, object := range after {
+		afterSet[object] = true
+	}
+	beforeSet := map[string]bool{}
+	for _, object := range before {
+		beforeSet[object] = true
+		if !afterSet[object] {
+			missing = append(missing, object)
+		}
+	}
+	for _, object := range after {
+		if !beforeSet[object] {
+			redundant = append(redundant, object)
+		}
+	}
+	return missing, redundant
+}
+
+func roundTripStepError(log MigrationLog, d direction, err error) error {
+	return fmt.Errorf("%s of migration %d (%s) in repo %s failed: %w", d, log.Idx, log.Description, log.Repo, err)
+}
+
+type schemaStore interface {
+	store
+	fetchSchemaSnapshot() ([]string, error)
+}
+
+// RoundTripError is returned by VerifyRoundTrip when schema after applying Up and Down
+// differs from schema before applying Up.
+// Missing contains schema objects existing only before applying migration,
+// Redundant contains schema objects existing only after applying Up and Down.
+type RoundTripError struct {
+	Repo        Repo
+	Idx         int
+	Description string
+	Missing     []string
+	Redundant   []string
+}
+
+func (e *RoundTripError) Error() string {
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "down of migration %d (%s) in repo %s does not reverse up", e.Idx, e.Description, e.Repo)
+	for _, object := range e.Missing {
+		_, _ = fmt.Fprintf(&b, "\n\t- %s", object)
+	}
+	for _, object := range e.Redundant {
+		_, _ = fmt.Fprintf(&b, "\n\t+ %s", object)
+	}
+	return b.String()
+}
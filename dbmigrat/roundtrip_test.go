@@ -0,0 +1,68 @@
+package dbmigrat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyRoundTrip(t *testing.T) {
+	t.Run("down reverses up", func(t *testing.T) {
+		assert.NoError(t, th.resetDB())
+		assert.NoError(t, th.pgStore.CreateLogTable())
+
+		verifiedCount, err := VerifyRoundTrip(th.pgStore, th.migrations2, RepoOrder{"auth", "billing", "delivery"})
+		assert.NoError(t, err)
+		assert.Equal(t, 5, verifiedCount)
+
+		// # Verified migrations stay applied
+		logCount, err := Migrate(th.pgStore, th.migrations2, RepoOrder{"auth", "billing", "delivery"})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, logCount)
+	})
+
+	t.Run("down does not reverse up", func(t *testing.T) {
+		assert.NoError(t, th.resetDB())
+		assert.NoError(t, th.pgStore.CreateLogTable())
+		migrations := Migrations{
+			"auth": {
+				th.migrations1["auth"][0],
+				{Up: `alter table users add column username varchar(32) not null default ''`, Down: `alter table users alter column username drop not null`, Description: "add username column"},
+			},
+		}
+
+		verifiedCount, err := VerifyRoundTrip(th.pgStore, migrations, RepoOrder{"auth"})
+		assert.Equal(t, 1, verifiedCount)
+		assert.Equal(t, &RoundTripError{
+			Repo:        "auth",
+			Idx:         1,
+			Description: "add username column",
+			Redundant:   []string{"column public.users.username character varying nullable=YES default=''::character varying"},
+		}, err)
+	})
+
+	t.Run("down fails", func(t *testing.T) {
+		assert.NoError(t, th.resetDB())
+		assert.NoError(t, th.pgStore.CreateLogTable())
+		migrations := Migrations{"auth": {{Up: th.migrations1["auth"][0].Up, Down: `drop table user`, Description: "create user table"}}}
+
+		verifiedCount, err := VerifyRoundTrip(th.pgStore, migrations, RepoOrder{"auth"})
+		assert.Equal(t, 0, verifiedCount)
+		assert.ErrorContains(t, err, "down of migration 0 (create user table) in repo auth failed")
+	})
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	missing, redundant := diffSnapshots([]string{"a", "b", "c"}, []string{"b", "c", "d"})
+	assert.Equal(t, []string{"a"}, missing)
+	assert.Equal(t, []string{"d"}, redundant)
+
+	missing, redundant = diffSnapshots([]string{"a"}, []string{"a"})
+	assert.Empty(t, missing)
+	assert.Empty(t, redundant)
+}
+
+func TestRoundTripError(t *testing.T) {
+	err := &RoundTripError{Repo: "auth", Idx: 1, Description: "add column", Missing: []string{"index a"}, Redundant: []string{"column b"}}
+	assert.EqualError(t, err, "down of migration 1 (add column) in repo auth does not reverse up\n\t- index a\n\t+ column b")
+}
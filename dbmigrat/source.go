@@ -0,0 +1,41 @@
+package dbmigrat
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+
+	"github.com/graaphscom/monogo/dbmigrat/internal/memfs"
+)
+
+// ZipFS returns file system of zip archive.
+func ZipFS(r io.ReaderAt, size int64) (fs.FS, error) {
+	return zip.NewReader(r, size)
+}
+
+// TarFS reads tar archive into memory and returns its file system.
+// Compressed archive must be decompressed first (e.g. with gzip.NewReader).
+func TarFS(r io.Reader) (fs.FS, error) {
+	fileSys := memfs.FS{}
+	tarReader := tar.NewReader(r)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return fileSys, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, err
+		}
+		fileSys[path.Clean(header.Name)] = &memfs.File{Data: data, Mode: fs.FileMode(header.Mode).Perm(), ModTime: header.ModTime}
+	}
+}
@@ -0,0 +1,60 @@
+package dbmigrat
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var sourceFiles = map[string]string{
+	"auth/migrations/0.create_users.up.sql":   "create table users (id serial primary key)",
+	"auth/migrations/0.create_users.down.sql": "drop table users",
+}
+
+var sourceMigrations = []Migration{
+	{Description: "create_users", Up: "create table users (id serial primary key)", Down: "drop table users"},
+}
+
+func TestZipFS(t *testing.T) {
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	for name, content := range sourceFiles {
+		file, err := writer.Create(name)
+		assert.NoError(t, err)
+		_, err = file.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, writer.Close())
+
+	fileSys, err := ZipFS(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+	assert.NoError(t, err)
+	migrations, err := ReadDir(fileSys, "auth/migrations")
+	assert.NoError(t, err)
+	assert.Equal(t, sourceMigrations, migrations)
+}
+
+func TestTarFS(t *testing.T) {
+	var archive bytes.Buffer
+	writer := tar.NewWriter(&archive)
+	assert.NoError(t, writer.WriteHeader(&tar.Header{Name: "auth/migrations/", Typeflag: tar.TypeDir, Mode: 0755}))
+	for name, content := range sourceFiles {
+		assert.NoError(t, writer.WriteHeader(&tar.Header{Name: "./" + name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))}))
+		_, err := writer.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, writer.Close())
+
+	fileSys, err := TarFS(&archive)
+	assert.NoError(t, err)
+	migrations, err := ReadDir(fileSys, "auth/migrations")
+	assert.NoError(t, err)
+	assert.Equal(t, sourceMigrations, migrations)
+
+	t.Run("returns error for invalid archive", func(t *testing.T) {
+		_, err := TarFS(bytes.NewReader([]byte("not a tar archive")))
+		assert.Error(t, err)
+	})
+}
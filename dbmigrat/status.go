@@ -0,0 +1,148 @@
+package dbmigrat
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/graaphscom/monogo/asciiui"
+)
+
+// FetchStatus returns applied and pending migrations of every repo.
+// Repos are listed in repoOrder, repos not present in repoOrder are appended sorted by name
+// (it includes repos which exist only in migrations log).
+// Pending migrations are migrations missing from migrations log, including gaps
+// before the last applied migration (see MigrateOptions.OutOfOrder).
+func FetchStatus(s store, migrations Migrations, repoOrder RepoOrder) (*Status, error) {
+	logs, err := s.fetchAllMigrationLogs()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(logs, func(i, j int) bool { return logs[i].Idx < logs[j].Idx })
+
+	logsByRepo := map[Repo][]MigrationLog{}
+	for _, log := range logs {
+		logsByRepo[log.Repo] = append(logsByRepo[log.Repo], log)
+	}
+	appliedIndexes := appliedIndexesByRepo(logs)
+
+	repos := append(RepoOrder{}, repoOrder...)
+	listed := map[Repo]bool{}
+	for _, repo := range repoOrder {
+		listed[repo] = true
+	}
+	var unlisted []Repo
+	for repo := range migrations {
+		if !listed[repo] {
+			listed[repo] = true
+			unlisted = append(unlisted, repo)
+		}
+	}
+	for repo := range logsByRepo {
+		if !listed[repo] {
+			listed[repo] = true
+			unlisted = append(unlisted, repo)
+		}
+	}
+	sort.Slice(unlisted, func(i, j int) bool { return unlisted[i] < unlisted[j] })
+	repos = append(repos, unlisted...)
+
+	status := &Status{Repos: make([]RepoStatus, 0, len(repos))}
+	for _, repo := range repos {
+		repoStatus := RepoStatus{Repo: repo, Applied: logsByRepo[repo], Pending: []PendingMigration{}}
+		if repoStatus.Applied == nil {
+			repoStatus.Applied = []MigrationLog{}
+		}
+		for idx := range migrations[repo] {
+			if appliedIndexes[repo][idx] {
+				continue
+			}
+			repoStatus.Pending = append(repoStatus.Pending, PendingMigration{Idx: idx, Description: migrations[repo][idx].Description})
+		}
+		status.Repos = append(status.Repos, repoStatus)
+	}
+
+	return status, nil
+}
+
+// Table renders status as asciiui.Table with one row per migration.
+// Duration and Rows columns show statistics of applying migration (see MigrationLog).
+func (s Status) Table() asciiui.Table {
+	table := asciiui.Table{Rows: []asciiui.TableRow{
+		tableRow("Repo", "Idx", "Description", "State", "Serial", "Applied at", "Duration", "Rows"),
+	}}
+	for _, repo := range s.Repos {
+		for _, log := range repo.Applied {
+			rowsAffected := ""
+			if log.RowsAffected != nil {
+				rowsAffected = strconv.FormatInt(*log.RowsAffected, 10)
+			}
+			table.Rows = append(table.Rows, tableRow(
+				string(repo.Repo),
+				strconv.Itoa(log.Idx),
+				log.Description,
+				"applied",
+				strconv.Itoa(log.MigrationSerial),
+				log.AppliedAt.Format(time.RFC3339),
+				(time.Duration(log.DurationMs)*time.Millisecond).String(),
+				rowsAffected,
+			))
+		}
+		for _, migration := range repo.Pending {
+			table.Rows = append(table.Rows, tableRow(string(repo.Repo), strconv.Itoa(migration.Idx), migration.Description, "pending", "", "", "", ""))
+		}
+	}
+	return table
+}
+
+// Table renders found problems as asciiui.Table with one row per problem.
+func (r IntegrityCheckResult) Table() asciiui.Table {
+	table := asciiui.Table{Rows: []asciiui.TableRow{
+		tableRow("Repo", "Idx", "Description", "Problem"),
+	}}
+	for _, repo := range sortedRepos(r.RedundantRepos) {
+		table.Rows = append(table.Rows, tableRow(string(repo), "", "", "repo not present in migrations"))
+	}
+	addLogs := func(logs map[Repo][]MigrationLog, problem string) {
+		for _, repo := range sortedRepos(logs) {
+			for _, log := range logs[repo] {
+				table.Rows = append(table.Rows, tableRow(string(repo), strconv.Itoa(log.Idx), log.Description, problem))
+			}
+		}
+	}
+	addLogs(r.RedundantMigrations, "migration not present in migrations")
+	addLogs(r.InvalidChecksums, "invalid checksum")
+	addLogs(r.ConflictingMigrations, "conflicting migration")
+	for _, repo := range sortedRepos(r.Gaps) {
+		for _, gap := range r.Gaps[repo] {
+			table.Rows = append(table.Rows, tableRow(string(repo), strconv.Itoa(gap.Idx), gap.Description, "not applied (gap)"))
+		}
+	}
+	return table
+}
+
+func tableRow(texts ...string) asciiui.TableRow {
+	row := asciiui.TableRow{Cells: make([]asciiui.TableCell, 0, len(texts))}
+	for _, text := range texts {
+		row.Cells = append(row.Cells, asciiui.TableCell{Text: text})
+	}
+	return row
+}
+
+// Status describes applied and pending migrations (see FetchStatus).
+type Status struct {
+	Repos []RepoStatus `json:"repos"`
+}
+
+// RepoStatus describes applied and pending migrations of single repo.
+type RepoStatus struct {
+	Repo    Repo               `json:"repo"`
+	Applied []MigrationLog     `json:"applied"`
+	Pending []PendingMigration `json:"pending"`
+}
+
+// PendingMigration describes migration which is not applied yet.
+type PendingMigration struct {
+	Idx         int    `json:"idx"`
+	Description string `json:"description"`
+}
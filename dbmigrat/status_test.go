@@ -0,0 +1,100 @@
+package dbmigrat
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchStatus(t *testing.T) {
+	assert.NoError(t, th.resetDB())
+	assert.NoError(t, th.pgStore.CreateLogTable())
+
+	_, err := Migrate(th.pgStore, th.migrations1, RepoOrder{"auth", "billing"})
+	assert.NoError(t, err)
+
+	status, err := FetchStatus(th.pgStore, th.migrations2, RepoOrder{"auth", "billing"})
+	assert.NoError(t, err)
+	type repoStatus struct {
+		Repo    Repo
+		Applied []int
+		Pending []PendingMigration
+	}
+	var repos []repoStatus
+	for _, repo := range status.Repos {
+		var applied []int
+		for _, log := range repo.Applied {
+			applied = append(applied, log.Idx)
+		}
+		repos = append(repos, repoStatus{Repo: repo.Repo, Applied: applied, Pending: repo.Pending})
+	}
+	assert.Equal(t, []repoStatus{
+		{Repo: "auth", Applied: []int{0, 1}, Pending: []PendingMigration{}},
+		{Repo: "billing", Applied: []int{0}, Pending: []PendingMigration{{Idx: 1, Description: "add value gross column"}}},
+		{Repo: "delivery", Pending: []PendingMigration{{Idx: 0, Description: "create delivery status table"}}},
+	}, repos)
+
+	t.Run("db error", func(t *testing.T) {
+		res, err := FetchStatus(errorStoreMock{wrapped: th.pgStore, errFetchAllMigrationLogs: true}, th.migrations2, RepoOrder{})
+		assert.EqualError(t, err, exampleErr.Error())
+		assert.Nil(t, res)
+	})
+}
+
+func TestStatusTable(t *testing.T) {
+	rowsAffected := int64(3)
+	status := Status{Repos: []RepoStatus{{
+		Repo:    "auth",
+		Applied: []MigrationLog{{Idx: 0, Repo: "auth", MigrationSerial: 0, AppliedAt: time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC), Description: "create users", DurationMs: 1500, RowsAffected: &rowsAffected}},
+		Pending: []PendingMigration{{Idx: 1, Description: "add username"}},
+	}}}
+
+	rendered, err := status.Table().Render()
+	assert.NoError(t, err)
+	assert.Equal(t, `+------+-----+--------------+---------+--------+----------------------+----------+------+
+| Repo | Idx | Description  | State   | Serial | Applied at           | Duration | Rows |
++------+-----+--------------+---------+--------+----------------------+----------+------+
+| auth | 0   | create users | applied | 0      | 2022-06-01T12:00:00Z | 1.5s     | 3    |
++------+-----+--------------+---------+--------+----------------------+----------+------+
+| auth | 1   | add username | pending |        |                      |          |      |
++------+-----+--------------+---------+--------+----------------------+----------+------+
+`, rendered)
+
+	encoded, err := json.Marshal(status)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"repos": [{
+		"repo": "auth",
+		"applied": [{"idx": 0, "repo": "auth", "migrationSerial": 0, "checksum": "", "appliedAt": "2022-06-01T12:00:00Z", "description": "create users", "durationMs": 1500, "rowsAffected": 3}],
+		"pending": [{"idx": 1, "description": "add username"}]
+	}]}`, string(encoded))
+}
+
+func TestIntegrityCheckResultTable(t *testing.T) {
+	result := newIntegrityCheckResult()
+	result.IsCorrupted = true
+	result.RedundantRepos["legacy"] = true
+	result.InvalidChecksums["auth"] = []MigrationLog{{Idx: 1, Repo: "auth", Description: "add email"}}
+	result.ConflictingMigrations["auth"] = result.InvalidChecksums["auth"]
+	result.Gaps["auth"] = []PendingMigration{{Idx: 0, Description: "hotfix"}}
+
+	rendered, err := result.Table().Render()
+	assert.NoError(t, err)
+	assert.Equal(t, `+--------+-----+-------------+--------------------------------+
+| Repo   | Idx | Description | Problem                        |
++--------+-----+-------------+--------------------------------+
+| legacy |     |             | repo not present in migrations |
++--------+-----+-------------+--------------------------------+
+| auth   | 1   | add email   | invalid checksum               |
++--------+-----+-------------+--------------------------------+
+| auth   | 1   | add email   | conflicting migration          |
++--------+-----+-------------+--------------------------------+
+| auth   | 0   | hotfix      | not applied (gap)              |
++--------+-----+-------------+--------------------------------+
+`, rendered)
+
+	encoded, err := json.Marshal(result)
+	assert.NoError(t, err)
+	assert.Contains(t, string(encoded), `"redundantRepos":{"legacy":true}`)
+}
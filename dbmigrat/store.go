@@ -4,30 +4,28 @@ import (
 	"database/sql"
 	"time"
 
+	"github.com/hashicorp/go-multierror"
 	"github.com/jmoiron/sqlx"
 )
 
-// CreateLogTable creates table in db where applied migrations will be saved.
+// CreateLogTable creates tables in db where applied migrations, their history and applied repeatable migrations
+// will be saved. When tables already exist, CreateLogTable upgrades them to the version required
+// by this version of dbmigrat (see metaMigrations).
 // This should be called before use of other functions from dbmigrat lib.
 func (s PostgresStore) CreateLogTable() error {
-	_, err := s.getDbAccessor().Exec(`
-		create table if not exists dbmigrat_log
-		(
-		    idx              integer      not null,
-		    repo             varchar(255) not null,
-		    migration_serial integer      not null,
-		    checksum         bytea        not null,
-		    applied_at       timestamp    not null default current_timestamp,
-		    description      text         not null,
-		    primary key (idx, repo)
-		)
-	`)
-
-	return err
+	tx, err := s.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	err = migrateMeta(tx)
+	if err != nil {
+		return multierror.Append(err, tx.Rollback())
+	}
+	return tx.Commit()
 }
 
-func (s PostgresStore) fetchAllMigrationLogs() ([]migrationLog, error) {
-	var migrationLogs []migrationLog
+func (s PostgresStore) fetchAllMigrationLogs() ([]MigrationLog, error) {
+	var migrationLogs []MigrationLog
 	err := s.getDbAccessor().Select(&migrationLogs, `select * from dbmigrat_log`)
 	return migrationLogs, err
 }
@@ -44,10 +42,10 @@ func (s PostgresStore) fetchLastMigrationSerial() (int, error) {
 	return int(result.Int32), nil
 }
 
-func (s PostgresStore) insertLogs(logs []migrationLog) error {
+func (s PostgresStore) insertLogs(logs []MigrationLog) error {
 	_, err := s.getDbAccessor().NamedExec(`
-			insert into dbmigrat_log (idx, repo, migration_serial, checksum, applied_at, description)
-			values (:idx, :repo, :migration_serial, :checksum, default, :description)
+			insert into dbmigrat_log (idx, repo, migration_serial, checksum, applied_at, description, duration_ms, rows_affected)
+			values (:idx, :repo, :migration_serial, :checksum, default, :description, :duration_ms, :rows_affected)
 			`,
 		logs,
 	)
@@ -78,7 +76,7 @@ func (s PostgresStore) fetchReverseMigrationIndexesAfterSerial(serial int) (map[
 		Idx  int
 		Repo Repo
 	}
-	err := s.getDbAccessor().Select(&dest, `select idx, repo from dbmigrat_log where migration_serial > $1 order by idx desc`, serial)
+	err := s.getDbAccessor().Select(&dest, `select idx, repo from dbmigrat_log where migration_serial > $1 order by migration_serial desc, idx desc`, serial)
 	if err != nil {
 		return nil, err
 	}
@@ -91,7 +89,7 @@ func (s PostgresStore) fetchReverseMigrationIndexesAfterSerial(serial int) (map[
 	return repoToReverseMigrationIndexes, nil
 }
 
-func (s PostgresStore) deleteLogs(logs []migrationLog) error {
+func (s PostgresStore) deleteLogs(logs []MigrationLog) error {
 	for _, log := range logs {
 		_, err := s.getDbAccessor().Exec(`delete from dbmigrat_log where idx = $1 and repo = $2`, log.Idx, log.Repo)
 		if err != nil {
@@ -101,6 +99,80 @@ func (s PostgresStore) deleteLogs(logs []migrationLog) error {
 	return nil
 }
 
+func (s PostgresStore) insertHistory(entries []HistoryEntry) error {
+	_, err := s.getDbAccessor().NamedExec(`
+			insert into dbmigrat_history (idx, repo, migration_serial, checksum, description, action, outcome, error, actor, host, recorded_at)
+			values (:idx, :repo, :migration_serial, :checksum, :description, :action, :outcome, :error, :actor, :host, default)
+			`,
+		entries,
+	)
+
+	return err
+}
+
+func (s PostgresStore) fetchHistory() ([]HistoryEntry, error) {
+	var entries []HistoryEntry
+	err := s.getDbAccessor().Select(&entries, `select * from dbmigrat_history order by id`)
+	return entries, err
+}
+
+func (s PostgresStore) fetchRepeatableLogs() ([]RepeatableLog, error) {
+	var logs []RepeatableLog
+	err := s.getDbAccessor().Select(&logs, `select * from dbmigrat_repeatable_log`)
+	return logs, err
+}
+
+func (s PostgresStore) upsertRepeatableLog(log RepeatableLog) error {
+	_, err := s.getDbAccessor().NamedExec(`
+			insert into dbmigrat_repeatable_log (repo, name, checksum, applied_at)
+			values (:repo, :name, :checksum, default)
+			on conflict (repo, name) do update set checksum = excluded.checksum, applied_at = excluded.applied_at
+			`,
+		log,
+	)
+
+	return err
+}
+
+func (s PostgresStore) fetchAvailableExtensions() (map[string]bool, error) {
+	var names []string
+	err := s.getDbAccessor().Select(&names, `select name from pg_available_extensions`)
+	if err != nil {
+		return nil, err
+	}
+	extensions := map[string]bool{}
+	for _, name := range names {
+		extensions[name] = true
+	}
+	return extensions, nil
+}
+
+func (s PostgresStore) fetchImportedVersions(tool importedTool, table quotedIdentifier) ([]importedVersion, error) {
+	rows, err := s.getDbAccessor().Query(tool.queryFrom(table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var versions []importedVersion
+	for rows.Next() {
+		var version importedVersion
+		err := rows.Scan(&version.version, &version.applied, &version.baseline, &version.dirty)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+	return versions, rows.Err()
+}
+
+// fetchSchemaSnapshot describes schema objects (except dbmigrat's tables) existing in search path.
+// Every object is described by a single line, lines are sorted.
+func (s PostgresStore) fetchSchemaSnapshot() ([]string, error) {
+	var snapshot []string
+	err := s.getDbAccessor().Select(&snapshot, schemaSnapshotQuery)
+	return snapshot, err
+}
+
 func (s *PostgresStore) begin() error {
 	tx, err := s.DB.Beginx()
 	s.tx = tx
@@ -108,6 +180,9 @@ func (s *PostgresStore) begin() error {
 }
 
 func (s *PostgresStore) rollback() error {
+	if s.tx == nil {
+		return nil
+	}
 	err := s.tx.Rollback()
 	s.tx = nil
 	return err
@@ -119,9 +194,13 @@ func (s *PostgresStore) commit() error {
 	return err
 }
 
-func (s PostgresStore) exec(query string) error {
-	_, err := s.getDbAccessor().Exec(query)
-	return err
+// exec executes query and returns count of rows affected by its last statement.
+func (s PostgresStore) exec(query string) (int64, error) {
+	result, err := s.getDbAccessor().Exec(query)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 func (s PostgresStore) getDbAccessor() dbAccessor {
@@ -133,6 +212,7 @@ func (s PostgresStore) getDbAccessor() dbAccessor {
 
 type dbAccessor interface {
 	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
 	NamedExec(query string, arg interface{}) (sql.Result, error)
 	Select(dest interface{}, query string, args ...interface{}) error
 	Get(dest interface{}, query string, args ...interface{}) error
@@ -143,25 +223,81 @@ type PostgresStore struct {
 	tx *sqlx.Tx
 }
 
+// Store is implemented by every store of this package (PostgresStore, PgxStore, MemoryStore and stores
+// returned by NewPostgresTxStore and NewPgxTxStore). It allows for accepting any store outside of this package.
+type Store interface {
+	store
+}
+
 type store interface {
 	CreateLogTable() error
-	fetchAllMigrationLogs() ([]migrationLog, error)
+	fetchAllMigrationLogs() ([]MigrationLog, error)
 	fetchLastMigrationSerial() (int, error)
-	insertLogs(logs []migrationLog) error
+	insertLogs(logs []MigrationLog) error
 	fetchLastMigrationIndexes() (map[Repo]int, error)
 	fetchReverseMigrationIndexesAfterSerial(serial int) (map[Repo][]int, error)
-	deleteLogs(logs []migrationLog) error
+	deleteLogs(logs []MigrationLog) error
+	insertHistory(entries []HistoryEntry) error
+	fetchHistory() ([]HistoryEntry, error)
+	fetchAvailableExtensions() (map[string]bool, error)
+	fetchRepeatableLogs() ([]RepeatableLog, error)
+	upsertRepeatableLog(log RepeatableLog) error
+	// fetchImportedVersions reads versions applied by other tool (see importTool).
+	fetchImportedVersions(tool importedTool, table quotedIdentifier) ([]importedVersion, error)
 	begin() error
 	rollback() error
 	commit() error
-	exec(query string) error
+	exec(query string) (int64, error)
 }
 
-type migrationLog struct {
-	Idx             int
-	Repo            Repo
-	MigrationSerial int `db:"migration_serial"`
-	Checksum        string
-	AppliedAt       time.Time `db:"applied_at"`
-	Description     string
+// MigrationLog represents migration saved in migrations log table.
+type MigrationLog struct {
+	Idx             int       `json:"idx"`
+	Repo            Repo      `json:"repo"`
+	MigrationSerial int       `db:"migration_serial" json:"migrationSerial"`
+	Checksum        string    `json:"checksum"`
+	AppliedAt       time.Time `db:"applied_at" json:"appliedAt"`
+	Description     string    `json:"description"`
+	// DurationMs is duration of executing migration's Up in milliseconds.
+	DurationMs int64 `db:"duration_ms" json:"durationMs"`
+	// RowsAffected is count of rows affected by the last statement of DML migration (nil for other migrations).
+	RowsAffected *int64 `db:"rows_affected" json:"rowsAffected"`
 }
+
+// schemaSnapshotQuery is used by fetchSchemaSnapshot of PostgresStore and PgxStore.
+const schemaSnapshotQuery = `
+	with schemas as (select oid, nspname from pg_namespace where nspname = any (current_schemas(false)))
+	select 'column ' || table_schema || '.' || table_name || '.' || column_name || ' ' || data_type ||
+	       ' nullable=' || is_nullable || ' default=' || coalesce(column_default, '')
+	from information_schema.columns
+	where table_schema in (select nspname from schemas) and table_name not like 'dbmigrat\_%'
+	union all
+	select 'index ' || indexdef
+	from pg_indexes
+	where schemaname in (select nspname from schemas) and tablename not like 'dbmigrat\_%'
+	union all
+	select 'constraint ' || conrelid::regclass::text || ' ' || conname || ' ' || pg_get_constraintdef(oid)
+	from pg_constraint
+	where connamespace in (select oid from schemas) and conrelid::regclass::text not like 'dbmigrat\_%'
+	union all
+	select 'view ' || schemaname || '.' || viewname || ' ' || definition
+	from pg_views
+	where schemaname in (select nspname from schemas)
+	union all
+	select 'sequence ' || schemaname || '.' || sequencename
+	from pg_sequences
+	where schemaname in (select nspname from schemas) and sequencename not like 'dbmigrat\_%'
+	union all
+	select 'function ' || pg_get_functiondef(p.oid)
+	from pg_proc p
+	where pronamespace in (select oid from schemas) and prokind in ('f', 'p')
+	union all
+	select 'trigger ' || pg_get_triggerdef(oid)
+	from pg_trigger
+	where not tgisinternal and tgrelid in (select oid from pg_class where relnamespace in (select oid from schemas))
+	union all
+	select 'type ' || t.typname || ' ' || coalesce((select string_agg(enumlabel, ',' order by enumsortorder) from pg_enum where enumtypid = t.oid), '')
+	from pg_type t
+	where typnamespace in (select oid from schemas) and typtype in ('d', 'e')
+	order by 1
+`
@@ -14,6 +14,35 @@ func TestCreateLogTable(t *testing.T) {
 	assert.NoError(t, th.pgStore.CreateLogTable())
 }
 
+func TestCreateLogTableUpgrade(t *testing.T) {
+	assert.NoError(t, th.resetDB())
+	// # Log table created before dbmigrat_meta was introduced
+	_, err := th.db.Exec(metaMigrations[0])
+	assert.NoError(t, err)
+	_, err = th.db.Exec(`insert into dbmigrat_log (idx, repo, migration_serial, checksum, description) values (0, 'auth', 0, $1, 'kept')`, sha1Checksum("up"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, th.pgStore.CreateLogTable())
+	var version int
+	assert.NoError(t, th.db.Get(&version, `select version from dbmigrat_meta`))
+	assert.Equal(t, len(metaMigrations)-1, version)
+	logs, err := th.pgStore.fetchAllMigrationLogs()
+	assert.NoError(t, err)
+	assert.Len(t, logs, 1)
+	// # metaMigrations[3] backfills added columns
+	assert.Equal(t, int64(0), logs[0].DurationMs)
+	assert.Nil(t, logs[0].RowsAffected)
+	history, err := th.pgStore.fetchHistory()
+	assert.NoError(t, err)
+	assert.Empty(t, history)
+
+	t.Run("tables upgraded by newer version", func(t *testing.T) {
+		_, err := th.db.Exec(`update dbmigrat_meta set version = version + 1`)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, th.pgStore.CreateLogTable(), errMetaVersionTooNew)
+	})
+}
+
 func TestFetchLastMigrationSerial(t *testing.T) {
 	// # Create empty migrations log
 	assert.NoError(t, th.resetDB())
@@ -26,7 +55,7 @@ func TestFetchLastMigrationSerial(t *testing.T) {
 	})
 
 	t.Run("Migrations log with one migration returns serial 0, no errors", func(t *testing.T) {
-		assert.NoError(t, th.pgStore.insertLogs([]migrationLog{{
+		assert.NoError(t, th.pgStore.insertLogs([]MigrationLog{{
 			Idx:             0,
 			Repo:            "foo",
 			MigrationSerial: 0,
@@ -39,7 +68,7 @@ func TestFetchLastMigrationSerial(t *testing.T) {
 	})
 
 	t.Run("Migrations log with two migrations returns serial 1, no errors", func(t *testing.T) {
-		assert.NoError(t, th.pgStore.insertLogs([]migrationLog{{
+		assert.NoError(t, th.pgStore.insertLogs([]MigrationLog{{
 			Idx:             1,
 			Repo:            "foo",
 			MigrationSerial: 1,
@@ -52,7 +81,7 @@ func TestFetchLastMigrationSerial(t *testing.T) {
 	})
 }
 func TestIndexesFetch(t *testing.T) {
-	complexMigrationLog := []migrationLog{
+	complexMigrationLog := []MigrationLog{
 		{
 			Idx:             0,
 			Repo:            "foo",
@@ -132,7 +161,7 @@ func TestNoDbLog(t *testing.T) {
 	})
 
 	t.Run("deleteLogs", func(t *testing.T) {
-		assert.EqualError(t, th.pgStore.deleteLogs([]migrationLog{{Idx: 0, Repo: "bar"}}), expectedErr)
+		assert.EqualError(t, th.pgStore.deleteLogs([]MigrationLog{{Idx: 0, Repo: "bar"}}), expectedErr)
 	})
 
 	t.Run("fetchLastMigrationIndexes", func(t *testing.T) {
@@ -151,7 +180,7 @@ func TestDeleteLogs(t *testing.T) {
 	assert.NoError(t, th.resetDB())
 	assert.NoError(t, th.pgStore.CreateLogTable())
 
-	assert.NoError(t, th.pgStore.insertLogs([]migrationLog{
+	assert.NoError(t, th.pgStore.insertLogs([]MigrationLog{
 		{
 			Idx:             0,
 			Repo:            "foo",
@@ -167,8 +196,8 @@ func TestDeleteLogs(t *testing.T) {
 			Description:     "",
 		},
 	}))
-	assert.NoError(t, th.pgStore.deleteLogs([]migrationLog{{Idx: 0, Repo: "bar"}}))
-	var migrationLogs []migrationLog
+	assert.NoError(t, th.pgStore.deleteLogs([]MigrationLog{{Idx: 0, Repo: "bar"}}))
+	var migrationLogs []MigrationLog
 	assert.NoError(t, th.db.Select(&migrationLogs, `select * from dbmigrat_log`))
 	assert.Len(t, migrationLogs, 1)
 	assert.Equal(t, 0, migrationLogs[0].Idx)
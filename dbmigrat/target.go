@@ -0,0 +1,46 @@
+package dbmigrat
+
+import "fmt"
+
+// ReleaseTarget returns Target containing migrations of release tagged with given tag (see Migration.Tags).
+// Repos without migration tagged with given tag are not present in returned Target,
+// so they are not migrated (e.g. repo was created after the release).
+func ReleaseTarget(migrations Migrations, tag string) (Target, error) {
+	target := Target{}
+	for repo, repoMigrations := range migrations {
+		for idx, migration := range repoMigrations {
+			if !migration.hasTag(tag) {
+				continue
+			}
+			if _, ok := target[repo]; ok {
+				return nil, fmt.Errorf("%w (%s, %s)", errDuplicatedTag, repo, tag)
+			}
+			target[repo] = idx
+		}
+	}
+	if len(target) == 0 {
+		return nil, fmt.Errorf("%w (%s)", errUnknownTag, tag)
+	}
+
+	return target, nil
+}
+
+func (m Migration) hasTag(tag string) bool {
+	return contains(m.Tags, tag)
+}
+
+func (t Target) validate(migrations Migrations) error {
+	for repo, idx := range t {
+		if idx < -1 || idx >= len(migrations[repo]) {
+			return fmt.Errorf("%w (%s, %d)", errTargetOutOfRange, repo, idx)
+		}
+	}
+	return nil
+}
+
+// Target maps repo to index of the last migration which should be applied.
+// Index -1 means that no migration of repo should be applied.
+// Repos not present in Target are not migrated.
+//
+// Migrate with Target never rolls back migrations applied after target index, use Rollback for this.
+type Target map[Repo]int
@@ -0,0 +1,37 @@
+package dbmigrat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReleaseTarget(t *testing.T) {
+	migrations := Migrations{
+		"auth": {
+			{Description: "create users", Tags: []string{"v1"}},
+			{Description: "add username", Tags: []string{"v2", "v3"}},
+			{Description: "add email"},
+		},
+		"billing": {
+			{Description: "create orders", Tags: []string{"v3"}},
+		},
+	}
+
+	target, err := ReleaseTarget(migrations, "v2")
+	assert.NoError(t, err)
+	assert.Equal(t, Target{"auth": 1}, target)
+
+	target, err = ReleaseTarget(migrations, "v3")
+	assert.NoError(t, err)
+	assert.Equal(t, Target{"auth": 1, "billing": 0}, target)
+
+	t.Run("unknown tag", func(t *testing.T) {
+		_, err := ReleaseTarget(migrations, "v4")
+		assert.ErrorIs(t, err, errUnknownTag)
+	})
+	t.Run("duplicated tag", func(t *testing.T) {
+		_, err := ReleaseTarget(Migrations{"auth": {{Tags: []string{"v1"}}, {Tags: []string{"v1"}}}}, "v1")
+		assert.ErrorIs(t, err, errDuplicatedTag)
+	})
+}
@@ -0,0 +1 @@
+create table users (id serial primary key);
\ No newline at end of file
@@ -0,0 +1 @@
+delete from users;
\ No newline at end of file
@@ -0,0 +1 @@
+insert into users default values;
\ No newline at end of file
@@ -0,0 +1 @@
+create table orders (id serial primary key, user_id integer references users (id) not null);
\ No newline at end of file
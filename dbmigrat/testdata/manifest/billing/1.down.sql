@@ -0,0 +1 @@
+drop index concurrently orders_user_id_idx;
\ No newline at end of file
@@ -0,0 +1 @@
+create or replace view orders_count as select count(*) from orders;
\ No newline at end of file
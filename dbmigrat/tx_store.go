@@ -0,0 +1,237 @@
+package dbmigrat
+
+import (
+	"github.com/hashicorp/go-multierror"
+	"github.com/jackc/pgx/v5"
+	"github.com/jmoiron/sqlx"
+)
+
+// NewPostgresTxStore returns store which works like PostgresStore, but runs everything in tx managed by caller
+// (e.g. test fixture which rolls back everything or tooling which creates schema and migrates atomically).
+// Instead of committing, dbmigrat releases savepoint, and instead of rolling back tx, it rolls back to savepoint
+// (and releases it), so tx is still usable after failed Migrate or Rollback. Nothing is persisted until caller commits tx.
+//
+// Migrations with NoTransaction can not be applied (or rolled back) with returned store.
+func NewPostgresTxStore(tx *sqlx.Tx) *PostgresTxStore {
+	return &PostgresTxStore{store: PostgresStore{tx: tx}}
+}
+
+// PostgresTxStore is returned by NewPostgresTxStore.
+type PostgresTxStore struct {
+	store     PostgresStore
+	savepoint savepoint
+}
+
+// CreateLogTable works like PostgresStore.CreateLogTable.
+func (s *PostgresTxStore) CreateLogTable() error {
+	err := s.begin()
+	if err != nil {
+		return err
+	}
+	err = migrateMeta(s.store.tx)
+	if err != nil {
+		return multierror.Append(err, s.rollback())
+	}
+	return s.commit()
+}
+
+func (s *PostgresTxStore) begin() error {
+	return s.savepoint.begin(s.store.exec)
+}
+
+func (s *PostgresTxStore) rollback() error {
+	return s.savepoint.rollback(s.store.exec)
+}
+
+func (s *PostgresTxStore) commit() error {
+	return s.savepoint.commit(s.store.exec)
+}
+
+func (s *PostgresTxStore) externalTransaction() {}
+
+func (s *PostgresTxStore) fetchAllMigrationLogs() ([]MigrationLog, error) {
+	return s.store.fetchAllMigrationLogs()
+}
+
+func (s *PostgresTxStore) fetchLastMigrationSerial() (int, error) {
+	return s.store.fetchLastMigrationSerial()
+}
+
+func (s *PostgresTxStore) insertLogs(logs []MigrationLog) error {
+	return s.store.insertLogs(logs)
+}
+
+func (s *PostgresTxStore) fetchLastMigrationIndexes() (map[Repo]int, error) {
+	return s.store.fetchLastMigrationIndexes()
+}
+
+func (s *PostgresTxStore) fetchReverseMigrationIndexesAfterSerial(serial int) (map[Repo][]int, error) {
+	return s.store.fetchReverseMigrationIndexesAfterSerial(serial)
+}
+
+func (s *PostgresTxStore) deleteLogs(logs []MigrationLog) error {
+	return s.store.deleteLogs(logs)
+}
+
+func (s *PostgresTxStore) insertHistory(entries []HistoryEntry) error {
+	return s.store.insertHistory(entries)
+}
+
+func (s *PostgresTxStore) fetchHistory() ([]HistoryEntry, error) {
+	return s.store.fetchHistory()
+}
+
+func (s *PostgresTxStore) fetchAvailableExtensions() (map[string]bool, error) {
+	return s.store.fetchAvailableExtensions()
+}
+
+func (s *PostgresTxStore) fetchRepeatableLogs() ([]RepeatableLog, error) {
+	return s.store.fetchRepeatableLogs()
+}
+
+func (s *PostgresTxStore) upsertRepeatableLog(log RepeatableLog) error {
+	return s.store.upsertRepeatableLog(log)
+}
+
+func (s *PostgresTxStore) fetchImportedVersions(tool importedTool, table quotedIdentifier) ([]importedVersion, error) {
+	return s.store.fetchImportedVersions(tool, table)
+}
+
+func (s *PostgresTxStore) fetchSchemaSnapshot() ([]string, error) {
+	return s.store.fetchSchemaSnapshot()
+}
+
+func (s *PostgresTxStore) exec(query string) (int64, error) {
+	return s.store.exec(query)
+}
+
+// NewPgxTxStore returns store which works like PgxStore, but runs everything in tx managed by caller
+// (see NewPostgresTxStore).
+func NewPgxTxStore(tx pgx.Tx) *PgxTxStore {
+	return &PgxTxStore{store: PgxStore{tx: tx}}
+}
+
+// PgxTxStore is returned by NewPgxTxStore.
+type PgxTxStore struct {
+	store     PgxStore
+	savepoint savepoint
+}
+
+// CreateLogTable works like PostgresStore.CreateLogTable.
+func (s *PgxTxStore) CreateLogTable() error {
+	err := s.begin()
+	if err != nil {
+		return err
+	}
+	err = migrateMeta(pgxMetaAccessor{tx: s.store.tx})
+	if err != nil {
+		return multierror.Append(err, s.rollback())
+	}
+	return s.commit()
+}
+
+func (s *PgxTxStore) begin() error {
+	return s.savepoint.begin(s.store.exec)
+}
+
+func (s *PgxTxStore) rollback() error {
+	return s.savepoint.rollback(s.store.exec)
+}
+
+func (s *PgxTxStore) commit() error {
+	return s.savepoint.commit(s.store.exec)
+}
+
+func (s *PgxTxStore) externalTransaction() {}
+
+func (s *PgxTxStore) fetchAllMigrationLogs() ([]MigrationLog, error) {
+	return s.store.fetchAllMigrationLogs()
+}
+
+func (s *PgxTxStore) fetchLastMigrationSerial() (int, error) {
+	return s.store.fetchLastMigrationSerial()
+}
+
+func (s *PgxTxStore) insertLogs(logs []MigrationLog) error {
+	return s.store.insertLogs(logs)
+}
+
+func (s *PgxTxStore) fetchLastMigrationIndexes() (map[Repo]int, error) {
+	return s.store.fetchLastMigrationIndexes()
+}
+
+func (s *PgxTxStore) fetchReverseMigrationIndexesAfterSerial(serial int) (map[Repo][]int, error) {
+	return s.store.fetchReverseMigrationIndexesAfterSerial(serial)
+}
+
+func (s *PgxTxStore) deleteLogs(logs []MigrationLog) error {
+	return s.store.deleteLogs(logs)
+}
+
+func (s *PgxTxStore) insertHistory(entries []HistoryEntry) error {
+	return s.store.insertHistory(entries)
+}
+
+func (s *PgxTxStore) fetchHistory() ([]HistoryEntry, error) {
+	return s.store.fetchHistory()
+}
+
+func (s *PgxTxStore) fetchAvailableExtensions() (map[string]bool, error) {
+	return s.store.fetchAvailableExtensions()
+}
+
+func (s *PgxTxStore) fetchRepeatableLogs() ([]RepeatableLog, error) {
+	return s.store.fetchRepeatableLogs()
+}
+
+func (s *PgxTxStore) upsertRepeatableLog(log RepeatableLog) error {
+	return s.store.upsertRepeatableLog(log)
+}
+
+func (s *PgxTxStore) fetchImportedVersions(tool importedTool, table quotedIdentifier) ([]importedVersion, error) {
+	return s.store.fetchImportedVersions(tool, table)
+}
+
+func (s *PgxTxStore) fetchSchemaSnapshot() ([]string, error) {
+	return s.store.fetchSchemaSnapshot()
+}
+
+func (s *PgxTxStore) exec(query string) (int64, error) {
+	return s.store.exec(query)
+}
+
+// savepoint emulates dbmigrat's transaction inside transaction managed by caller.
+// Savepoint is released after rolling back to it, so failed runs don't leave savepoints behind.
+type savepoint struct {
+	active bool
+}
+
+func (sp *savepoint) begin(exec func(query string) (int64, error)) error {
+	_, err := exec(`savepoint dbmigrat`)
+	sp.active = err == nil
+	return err
+}
+
+func (sp *savepoint) rollback(exec func(query string) (int64, error)) error {
+	if !sp.active {
+		return nil
+	}
+	sp.active = false
+	_, err := exec(`rollback to savepoint dbmigrat`)
+	if err != nil {
+		return err
+	}
+	_, err = exec(`release savepoint dbmigrat`)
+	return err
+}
+
+func (sp *savepoint) commit(exec func(query string) (int64, error)) error {
+	sp.active = false
+	_, err := exec(`release savepoint dbmigrat`)
+	return err
+}
+
+// externalTransactionStore is implemented by stores running in transaction managed by caller.
+type externalTransactionStore interface {
+	externalTransaction()
+}
@@ -0,0 +1,74 @@
+package dbmigrat
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostgresTxStore(t *testing.T) {
+	assert.NoError(t, th.resetDB())
+	tx, err := th.db.Beginx()
+	assert.NoError(t, err)
+	s := NewPostgresTxStore(tx)
+
+	testTxStore(t, s)
+
+	assert.NoError(t, tx.Rollback())
+	var tableCount int
+	assert.NoError(t, th.db.Get(&tableCount, `select count(*) from information_schema.tables where table_name like 'dbmigrat_%' or table_name = 'users'`))
+	assert.Equal(t, 0, tableCount)
+}
+
+func TestPgxTxStore(t *testing.T) {
+	assert.NoError(t, th.resetDB())
+	conn, err := pgx.Connect(context.Background(), os.Getenv("DBMIGRAT_TEST_DB_URL"))
+	assert.NoError(t, err)
+	defer conn.Close(context.Background())
+	tx, err := conn.Begin(context.Background())
+	assert.NoError(t, err)
+	s := NewPgxTxStore(tx)
+
+	testTxStore(t, s)
+
+	assert.NoError(t, tx.Commit(context.Background()))
+	result, err := CheckLogTableIntegrity(th.pgStore, th.migrations1)
+	assert.NoError(t, err)
+	assert.Equal(t, newIntegrityCheckResult(), result)
+}
+
+func testTxStore(t *testing.T, s store) {
+	assert.NoError(t, s.CreateLogTable())
+	assert.NoError(t, s.CreateLogTable())
+
+	logCount, err := Migrate(s, th.migrations1, RepoOrder{"auth", "billing"})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, logCount)
+
+	// # Failed migration rolls back to savepoint, so tx is still usable
+	invalid := Migrations{"auth": append(th.migrations1["auth"], Migration{Up: `invalid`, Down: `invalid`})}
+	_, err = Migrate(s, invalid, RepoOrder{"auth"})
+	assert.Error(t, err)
+
+	// # Savepoint is released after rolling back to it
+	_, err = s.exec(`savepoint probe`)
+	assert.NoError(t, err)
+	_, err = s.exec(`release savepoint dbmigrat`)
+	assert.Error(t, err)
+	_, err = s.exec(`rollback to savepoint probe`)
+	assert.NoError(t, err)
+	_, err = s.exec(`release savepoint probe`)
+	assert.NoError(t, err)
+
+	// # NoTransaction migration is rejected
+	noTx := Migrations{"auth": append(th.migrations1["auth"], Migration{Up: `create index concurrently users_id_idx on users (id)`, NoTransaction: true})}
+	_, err = Migrate(s, noTx, RepoOrder{"auth"})
+	assert.ErrorIs(t, err, errNoTransactionInExternalTx)
+
+	result, err := CheckLogTableIntegrity(s, th.migrations1)
+	assert.NoError(t, err)
+	assert.Equal(t, newIntegrityCheckResult(), result)
+}